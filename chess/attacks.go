@@ -0,0 +1,91 @@
+package chess
+
+// Precomputed attack tables for the non-sliding pieces, indexed by the
+// square the piece stands on. PawnAttacks is additionally indexed by color
+// since pawns capture in the direction they move.
+var (
+	KnightAttacks [64]Bitboard
+	KingAttacks   [64]Bitboard
+	PawnAttacks   [2][64]Bitboard
+)
+
+// Between[a][b] holds the squares strictly between a and b when the two
+// squares share a rank, file or diagonal, and is empty otherwise.
+// Line[a][b] holds the whole line (edge to edge) running through both
+// squares, including a and b, and is empty if they are not aligned.
+var (
+	Between [64][64]Bitboard
+	Line    [64][64]Bitboard
+)
+
+func init() {
+	for sq := 0; sq < 64; sq++ {
+		KnightAttacks[sq] = computeStepAttacks(sq, [][2]int{
+			{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2},
+		})
+		KingAttacks[sq] = computeStepAttacks(sq, [][2]int{
+			{0, 1}, {1, 1}, {1, 0}, {1, -1}, {0, -1}, {-1, -1}, {-1, 0}, {-1, 1},
+		})
+		PawnAttacks[White][sq] = computeStepAttacks(sq, [][2]int{{-1, 1}, {1, 1}})
+		PawnAttacks[Black][sq] = computeStepAttacks(sq, [][2]int{{-1, -1}, {1, -1}})
+	}
+
+	for a := 0; a < 64; a++ {
+		for b := 0; b < 64; b++ {
+			if a == b {
+				continue
+			}
+			bBit := Bitboard(1) << b
+
+			if ComputeRookAttacks(a, 0)&bBit != 0 {
+				Line[a][b] = (ComputeRookAttacks(a, 0) & ComputeRookAttacks(b, 0)) | (1 << a) | bBit
+				Between[a][b] = ComputeRookAttacks(a, bBit) & ComputeRookAttacks(b, 1<<a)
+			} else if ComputeBishopAttacks(a, 0)&bBit != 0 {
+				Line[a][b] = (ComputeBishopAttacks(a, 0) & ComputeBishopAttacks(b, 0)) | (1 << a) | bBit
+				Between[a][b] = ComputeBishopAttacks(a, bBit) & ComputeBishopAttacks(b, 1<<a)
+			}
+		}
+	}
+}
+
+// computeStepAttacks returns the squares reachable from square by each
+// (file, rank) offset, dropping any step that would leave the board.
+func computeStepAttacks(square int, steps [][2]int) Bitboard {
+	startRank := square / 8
+	startFile := square % 8
+
+	attacks := Bitboard(0)
+	for _, step := range steps {
+		file := startFile + step[0]
+		rank := startRank + step[1]
+		if file < 0 || file > 7 || rank < 0 || rank > 7 {
+			continue
+		}
+		attacks |= Bitboard(1) << (rank*8 + file)
+	}
+
+	return attacks
+}
+
+// RookAttacks returns the squares attacked by a rook on square, given the
+// occupancy of the board. The backend doing the work is chosen at build
+// time, see slider.go.
+func RookAttacks(square int, occupancy Bitboard) Bitboard {
+	return activeSliderAttacks{}.Rook(square, occupancy)
+}
+
+// BishopAttacks returns the squares attacked by a bishop on square, given
+// the occupancy of the board.
+func BishopAttacks(square int, occupancy Bitboard) Bitboard {
+	return activeSliderAttacks{}.Bishop(square, occupancy)
+}
+
+func QueenAttacks(square int, occupancy Bitboard) Bitboard {
+	return RookAttacks(square, occupancy) | BishopAttacks(square, occupancy)
+}
+
+// Aligned reports whether the three squares lie on the same rank, file or
+// diagonal.
+func Aligned(a, b, c int) bool {
+	return Line[a][b]&(Bitboard(1)<<c) != 0
+}
@@ -0,0 +1,117 @@
+package chess
+
+import (
+	"fmt"
+	"testing"
+)
+
+// builds a bitboard from a list of squares, e.g. squares("a1", "h8")
+func squares(names ...string) Bitboard {
+	bb := Bitboard(0)
+	for _, name := range names {
+		bb |= Bitboard(1) << RankFileToBitIndex(name[0], name[1])
+	}
+	return bb
+}
+
+func square(name string) int {
+	return RankFileToBitIndex(name[0], name[1])
+}
+
+func TestLeaperAttacks(t *testing.T) {
+	tests := []struct {
+		name     string
+		actual   Bitboard
+		expected Bitboard
+	}{
+		{"knight a1", KnightAttacks[square("a1")], squares("b3", "c2")},
+		{"knight d4", KnightAttacks[square("d4")], squares("c6", "e6", "f5", "f3", "e2", "c2", "b3", "b5")},
+		{"knight h8", KnightAttacks[square("h8")], squares("g6", "f7")},
+		{"king a1", KingAttacks[square("a1")], squares("a2", "b2", "b1")},
+		{"king e4", KingAttacks[square("e4")], squares("d5", "e5", "f5", "d4", "f4", "d3", "e3", "f3")},
+		{"white pawn a2", PawnAttacks[White][square("a2")], squares("b3")},
+		{"white pawn e4", PawnAttacks[White][square("e4")], squares("d5", "f5")},
+		{"white pawn h8", PawnAttacks[White][square("h8")], 0},
+		{"black pawn h7", PawnAttacks[Black][square("h7")], squares("g6")},
+		{"black pawn e4", PawnAttacks[Black][square("e4")], squares("d3", "f3")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.actual != tt.expected {
+				t.Errorf("expected: \n%sgot: \n%s", ToString(tt.expected), ToString(tt.actual))
+			}
+		})
+	}
+}
+
+func TestComputeBishopAttacks(t *testing.T) {
+	// bishop on d4 with blockers on f6 and b2
+	occupancy := squares("f6", "b2")
+
+	expected := squares(
+		"e5", "f6", // north east until f6
+		"c5", "b6", "a7", // north west until edge
+		"e3", "f2", "g1", // south east until edge
+		"c3", "b2", // south west until b2
+	)
+
+	got := ComputeBishopAttacks(square("d4"), occupancy)
+	if got != expected {
+		t.Errorf("expected: \n%sgot: \n%s", ToString(expected), ToString(got))
+	}
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		from, to string
+		expected Bitboard
+	}{
+		{"a1", "a8", squares("a2", "a3", "a4", "a5", "a6", "a7")},
+		{"a8", "a1", squares("a2", "a3", "a4", "a5", "a6", "a7")},
+		{"c1", "f1", squares("d1", "e1")},
+		{"b2", "g7", squares("c3", "d4", "e5", "f6")},
+		{"h1", "e4", squares("g2", "f3")},
+		{"e4", "e5", 0}, // adjacent
+		{"a1", "b3", 0}, // not aligned
+		{"d4", "d4", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s-%s", tt.from, tt.to), func(t *testing.T) {
+			got := Between[square(tt.from)][square(tt.to)]
+			if got != tt.expected {
+				t.Errorf("expected: \n%sgot: \n%s", ToString(tt.expected), ToString(got))
+			}
+		})
+	}
+}
+
+func TestLine(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected Bitboard
+	}{
+		{"c3", "c6", A_File << 2},
+		{"b4", "g4", 0xFF << 24},
+		{"c3", "e5", squares("a1", "b2", "c3", "d4", "e5", "f6", "g7", "h8")},
+		{"b7", "c6", squares("a8", "b7", "c6", "d5", "e4", "f3", "g2", "h1")},
+		{"a1", "b3", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s-%s", tt.a, tt.b), func(t *testing.T) {
+			got := Line[square(tt.a)][square(tt.b)]
+			if got != tt.expected {
+				t.Errorf("expected: \n%sgot: \n%s", ToString(tt.expected), ToString(got))
+			}
+		})
+	}
+
+	if !Aligned(square("a1"), square("h8"), square("d4")) {
+		t.Errorf("expected a1, h8 and d4 to be aligned")
+	}
+	if Aligned(square("a1"), square("h8"), square("d5")) {
+		t.Errorf("expected a1, h8 and d5 not to be aligned")
+	}
+}
@@ -2,10 +2,28 @@ package chess
 
 import (
 	"fmt"
+	"math/bits"
 )
 
 type Bitboard uint64
 
+const (
+	White = iota
+	Black
+)
+
+const (
+	Pawn = iota
+	Knight
+	Bishop
+	Rook
+	Queen
+	King
+)
+
+// used as the square of a piece that isn't on the board, e.g. a missing king
+const NoSquare = 64
+
 type Move struct {
 	From  int
 	To    int
@@ -40,10 +58,24 @@ type Position struct {
 	PieceMap [64]byte
 
 	SideToMove string
+
+	// plies since the last capture or pawn move, for the fifty move rule,
+	// and the number of the current move, starting at 1
+	HalfmoveClock  int
+	FullmoveNumber int
+
+	// Zobrist hash of the position, kept up to date by MakeMove
+	Hash uint64
+
+	// cached information about checks and pins, see UpdateState
+	State StateInfo
+
+	// one Undo record per move made, most recent last
+	history []Undo
 }
 
 func NewPosition() *Position {
-	return &Position{
+	pos := &Position{
 		WhitePawns:   0x000000000000FF00,
 		WhiteRooks:   0x0000000000000081,
 		WhiteKnights: 0x0000000000000042,
@@ -80,7 +112,14 @@ func NewPosition() *Position {
 		},
 
 		SideToMove: "white",
+
+		FullmoveNumber: 1,
 	}
+
+	pos.Hash = pos.ComputeHash()
+	pos.UpdateState()
+
+	return pos
 }
 
 func (p *Position) WhitePieces() Bitboard {
@@ -91,6 +130,124 @@ func (p *Position) BlackPieces() Bitboard {
 	return p.BlackBishops | p.BlackKing | p.BlackKnights | p.BlackPawns | p.BlackRooks | p.BlackQueens
 }
 
+// PieceType maps a piece character, e.g. 'N' or 'n', to its piece type
+func PieceType(piece byte) int {
+	switch piece {
+	case 'P', 'p':
+		return Pawn
+	case 'N', 'n':
+		return Knight
+	case 'B', 'b':
+		return Bishop
+	case 'R', 'r':
+		return Rook
+	case 'Q', 'q':
+		return Queen
+	case 'K', 'k':
+		return King
+	}
+	panic("Piece type unknown!")
+}
+
+// PieceColor returns White for upper case piece characters and Black otherwise
+func PieceColor(piece byte) int {
+	if piece >= 'A' && piece <= 'Z' {
+		return White
+	}
+	return Black
+}
+
+// ColorToMove returns the side to move as White or Black
+func (p *Position) ColorToMove() int {
+	if p.SideToMove == "white" {
+		return White
+	}
+	return Black
+}
+
+func (p *Position) PiecesOfColor(color int) Bitboard {
+	if color == White {
+		return p.WhitePieces()
+	}
+	return p.BlackPieces()
+}
+
+func (p *Position) PiecesOfType(color, pieceType int) Bitboard {
+	if color == White {
+		switch pieceType {
+		case Pawn:
+			return p.WhitePawns
+		case Knight:
+			return p.WhiteKnights
+		case Bishop:
+			return p.WhiteBishops
+		case Rook:
+			return p.WhiteRooks
+		case Queen:
+			return p.WhiteQueens
+		case King:
+			return p.WhiteKing
+		}
+	} else {
+		switch pieceType {
+		case Pawn:
+			return p.BlackPawns
+		case Knight:
+			return p.BlackKnights
+		case Bishop:
+			return p.BlackBishops
+		case Rook:
+			return p.BlackRooks
+		case Queen:
+			return p.BlackQueens
+		case King:
+			return p.BlackKing
+		}
+	}
+	return 0
+}
+
+// KingSquare returns the square of the king of the given color, or NoSquare
+// if that side has no king on the board
+func (p *Position) KingSquare(color int) int {
+	king := p.PiecesOfType(color, King)
+	if king == 0 {
+		return NoSquare
+	}
+	return bits.TrailingZeros64(uint64(king))
+}
+
+// pieceBitboard returns a pointer to the bitboard holding the given piece
+func (p *Position) pieceBitboard(piece byte) *Bitboard {
+	switch piece {
+	case 'P':
+		return &p.WhitePawns
+	case 'N':
+		return &p.WhiteKnights
+	case 'B':
+		return &p.WhiteBishops
+	case 'R':
+		return &p.WhiteRooks
+	case 'Q':
+		return &p.WhiteQueens
+	case 'K':
+		return &p.WhiteKing
+	case 'p':
+		return &p.BlackPawns
+	case 'n':
+		return &p.BlackKnights
+	case 'b':
+		return &p.BlackBishops
+	case 'r':
+		return &p.BlackRooks
+	case 'q':
+		return &p.BlackQueens
+	case 'k':
+		return &p.BlackKing
+	}
+	panic("Piece type unknown!")
+}
+
 func (p *Position) SetPiece(piece byte, square string) {
 	index := RankFileToBitIndex(square[0], square[1])
 	mask := Bitboard(1) << index
@@ -231,29 +388,6 @@ func AtBounds(index int) bool {
 	return leftBound || rightBound || upperBound || lowerBound
 }
 
-func (p *Position) applyPromotion(promotion byte, to int, toMask Bitboard) {
-	switch promotion {
-	case 'Q':
-		p.WhiteQueens |= toMask
-	case 'B':
-		p.WhiteBishops |= toMask
-	case 'N':
-		p.WhiteKnights |= toMask
-	case 'R':
-		p.WhiteRooks |= toMask
-	case 'q':
-		p.BlackQueens |= toMask
-	case 'b':
-		p.BlackBishops |= toMask
-	case 'n':
-		p.BlackKnights |= toMask
-	case 'r':
-		p.BlackRooks |= toMask
-	}
-
-	p.PieceMap[to] = promotion
-}
-
 func (p *Position) CaptureBlack(toMask Bitboard) {
 	p.BlackPawns &^= toMask
 	p.BlackKnights &^= toMask
@@ -270,59 +404,6 @@ func (p *Position) CaptureWhite(toMask Bitboard) {
 	p.WhiteQueens &^= toMask
 }
 
-func (p *Position) applyPawnMove(toMask, fromMask Bitboard, to, from int, promotion byte) {
-	diff := Abs(to - from)
-	isPush := diff == 8 || diff == 16
-
-	var movingPawns, opponentPawns *Bitboard
-	var pieceChar byte
-	var enPassantOffset int
-
-	if p.WhitePawns&fromMask != 0 {
-		movingPawns = &p.WhitePawns
-		opponentPawns = &p.BlackPawns
-		pieceChar = 'P'
-		enPassantOffset = -8
-	} else if p.BlackPawns&fromMask != 0 {
-		movingPawns = &p.BlackPawns
-		opponentPawns = &p.WhitePawns
-		pieceChar = 'p'
-		enPassantOffset = 8
-	}
-
-	*movingPawns &^= fromMask
-
-	if !isPush {
-		if toMask == p.EnPassantTarget {
-			capIdx := to + enPassantOffset
-			*opponentPawns &^= Bitboard(1) << capIdx
-			p.PieceMap[capIdx] = 0
-		} else {
-			if pieceChar == 'P' {
-				p.CaptureBlack(toMask)
-			} else {
-				p.CaptureWhite(toMask)
-			}
-		}
-	}
-
-	isBackRank := to/8 == 7
-	isFirstRank := to/8 == 0
-
-	if isBackRank {
-		promotion = ToUpper(promotion)
-	}
-
-	if isBackRank || isFirstRank {
-		p.applyPromotion(promotion, to, toMask)
-	} else {
-		*movingPawns |= toMask
-		p.PieceMap[to] = pieceChar
-	}
-
-	p.PieceMap[from] = 0
-}
-
 func (p *Position) changeTurn() {
 	if p.SideToMove == "white" {
 		p.SideToMove = "black"
@@ -342,48 +423,31 @@ func (p *Position) updateEnpassantState(pieceMoving byte, to, from int) {
 	}
 }
 
-func (p *Position) applyRookMove(toMask, fromMask Bitboard, to, from int) {
-	if p.SideToMove == "white" {
-		p.WhiteRooks &^= fromMask
-		p.WhiteRooks |= toMask
-		p.CaptureBlack(toMask)
-		p.PieceMap[to] = 'R'
-	} else {
-		p.BlackRooks &^= fromMask
-		p.BlackRooks |= toMask
-		p.CaptureWhite(toMask)
-		p.PieceMap[to] = 'r'
-	}
-	p.PieceMap[from] = 0
-
-	switch from {
-	case 0:
-		p.WhiteCastlingRights.Long = false
-	case 7:
-		p.WhiteCastlingRights.Short = false
-	case 56:
-		p.BlackCastlingRights.Long = false
-	case 63:
-		p.BlackCastlingRights.Short = false
+// updateCastlingRights clears castling rights once a king or rook leaves its
+// starting square, or a rook is captured on it
+func (p *Position) updateCastlingRights(to, from int) {
+	for _, sq := range [2]int{from, to} {
+		switch sq {
+		case 0:
+			p.WhiteCastlingRights.Long = false
+		case 7:
+			p.WhiteCastlingRights.Short = false
+		case 4:
+			p.WhiteCastlingRights = CastlingRights{}
+		case 56:
+			p.BlackCastlingRights.Long = false
+		case 63:
+			p.BlackCastlingRights.Short = false
+		case 60:
+			p.BlackCastlingRights = CastlingRights{}
+		}
 	}
 }
 
+// ApplyMove plays a move given in UCI notation, e.g. "e2e4" or "e7e8q".
+// It goes through MakeMove, so the move keeps the hash up to date, can be
+// taken back with UnmakeMove and counts towards repetitions.
 func (p *Position) ApplyMove(move string) {
 	from, to, promotion := ParseMove(move)
-	fromMask := Bitboard(1) << from
-	toMask := Bitboard(1) << to
-	pieceMoving := p.PieceMap[from]
-
-	switch pieceMoving {
-	case 'P', 'p':
-		p.applyPawnMove(toMask, fromMask, to, from, promotion)
-	case 'R', 'r':
-		p.applyRookMove(toMask, fromMask, to, from)
-	default:
-		panic("unexpected piece type")
-	}
-
-	p.updateEnpassantState(pieceMoving, to, from)
-
-	p.changeTurn()
+	p.MakeMove(Move{From: from, To: to, Promo: promotion})
 }
@@ -428,3 +428,50 @@ func TestBlackRookMoves(t *testing.T) {
 		t.Errorf("Expected long castling rights to remain true after rook move from h8")
 	}
 }
+
+func TestCastlingMoves(t *testing.T) {
+	pos := Position{
+		WhiteCastlingRights: CastlingRights{Short: true, Long: true},
+		BlackCastlingRights: CastlingRights{Short: true, Long: true},
+	}
+	pos.SetPiece('K', "e1")
+	pos.SetPiece('R', "h1")
+	pos.SetPiece('k', "e8")
+	pos.SetPiece('r', "a8")
+	pos.SideToMove = "white"
+
+	pos.ApplyMove("e1g1")
+	assertHasPiece(t, &pos, 'K', "g1")
+	assertHasPiece(t, &pos, 'R', "f1")
+	assertEmpty(t, &pos, "e1")
+	assertEmpty(t, &pos, "h1")
+	if pos.WhiteCastlingRights.Short || pos.WhiteCastlingRights.Long {
+		t.Errorf("expected white to lose both castling rights after castling")
+	}
+
+	pos.ApplyMove("e8c8")
+	assertHasPiece(t, &pos, 'k', "c8")
+	assertHasPiece(t, &pos, 'r', "d8")
+	assertEmpty(t, &pos, "a8")
+	if pos.BlackRooks != squares("d8") || pos.BlackKing != squares("c8") {
+		t.Errorf("failed to update black bitboards after castling")
+	}
+}
+
+func TestRookCaptureRemovesCastlingRights(t *testing.T) {
+	pos := Position{
+		BlackCastlingRights: CastlingRights{Short: true, Long: true},
+	}
+	pos.SetPiece('B', "b7")
+	pos.SetPiece('r', "a8")
+	pos.SideToMove = "white"
+
+	pos.ApplyMove("b7a8")
+	assertHasPiece(t, &pos, 'B', "a8")
+	if pos.BlackRooks != 0 {
+		t.Errorf("expected the black rook to be captured")
+	}
+	if pos.BlackCastlingRights.Long || !pos.BlackCastlingRights.Short {
+		t.Errorf("expected only black's long castling right to be removed")
+	}
+}
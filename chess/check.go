@@ -0,0 +1,88 @@
+package chess
+
+// GivesCheck reports whether the move, which must be pseudo-legal for the
+// side to move, would put the opponent in check. It works from the cached
+// state (see UpdateState) and doesn't make the move.
+func (p *Position) GivesCheck(move Move) bool {
+	piece := p.PieceMap[move.From]
+	if piece == 0 {
+		return false
+	}
+
+	us := p.ColorToMove()
+	them := us ^ 1
+	ksq := p.KingSquare(them)
+	if ksq == NoSquare {
+		return false
+	}
+
+	pieceType := PieceType(piece)
+	fromMask := Bitboard(1) << move.From
+	toMask := Bitboard(1) << move.To
+	isCastling := pieceType == King && Abs(move.To-move.From) == 2
+
+	// direct check
+	if move.Promo == 0 && p.State.CheckSquares[pieceType]&toMask != 0 {
+		return true
+	}
+
+	// discovered check: the piece was blocking one of our sliders and
+	// leaves the line to the king. Castling is handled below since the
+	// king and rook both move.
+	if !isCastling && p.State.Blockers[them]&fromMask != 0 && !Aligned(move.From, move.To, ksq) {
+		return true
+	}
+
+	occupancy := p.GetOccupiedSquares()
+
+	switch {
+	case move.Promo != 0:
+		occupancy &^= fromMask
+		switch PieceType(move.Promo) {
+		case Knight:
+			return KnightAttacks[move.To]&(1<<ksq) != 0
+		case Bishop:
+			return BishopAttacks(move.To, occupancy)&(1<<ksq) != 0
+		case Rook:
+			return RookAttacks(move.To, occupancy)&(1<<ksq) != 0
+		case Queen:
+			return QueenAttacks(move.To, occupancy)&(1<<ksq) != 0
+		}
+		return false
+
+	case pieceType == Pawn && toMask == p.EnPassantTarget:
+		// removing both pawns from the capturing rank can uncover a slider
+		captured := move.To - 8
+		if us == Black {
+			captured = move.To + 8
+		}
+		occupancy = occupancy&^fromMask&^(Bitboard(1)<<captured) | toMask
+
+		rooks := p.PiecesOfType(us, Rook) | p.PiecesOfType(us, Queen)
+		bishops := p.PiecesOfType(us, Bishop) | p.PiecesOfType(us, Queen)
+		return RookAttacks(ksq, occupancy)&rooks|BishopAttacks(ksq, occupancy)&bishops != 0
+
+	case isCastling:
+		rookFrom, rookTo := castlingRookSquares(move.To)
+		occupancy = occupancy&^fromMask&^(Bitboard(1)<<rookFrom) | toMask | (1 << rookTo)
+		return RookAttacks(rookTo, occupancy)&(1<<ksq) != 0
+	}
+
+	return false
+}
+
+// castlingRookSquares returns where the rook starts and ends up for the
+// castling move that takes the king to kingTo
+func castlingRookSquares(kingTo int) (from, to int) {
+	switch kingTo {
+	case 6: // g1
+		return 7, 5
+	case 2: // c1
+		return 0, 3
+	case 62: // g8
+		return 63, 61
+	case 58: // c8
+		return 56, 59
+	}
+	panic("not a castling destination")
+}
@@ -0,0 +1,179 @@
+package chess
+
+import "testing"
+
+func move(uci string) Move {
+	from, to, promo := ParseMove(uci)
+	return Move{From: from, To: to, Promo: promo}
+}
+
+func TestGivesCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(pos *Position)
+		move     string
+		expected bool
+	}{
+		{
+			name: "direct rook check",
+			setup: func(pos *Position) {
+				pos.SetPiece('R', "a2")
+			},
+			move:     "a2a8",
+			expected: true,
+		},
+		{
+			name: "quiet rook move",
+			setup: func(pos *Position) {
+				pos.SetPiece('R', "a2")
+			},
+			move:     "a2a7",
+			expected: false,
+		},
+		{
+			name: "direct knight check",
+			setup: func(pos *Position) {
+				pos.SetPiece('N', "g5")
+			},
+			move:     "g5f6",
+			expected: true,
+		},
+		{
+			name: "direct pawn check",
+			setup: func(pos *Position) {
+				pos.SetPiece('P', "d6")
+			},
+			move:     "d6d7",
+			expected: true,
+		},
+		{
+			name: "pawn push in front of the king",
+			setup: func(pos *Position) {
+				pos.SetPiece('P', "e6")
+			},
+			move:     "e6e7",
+			expected: false,
+		},
+		{
+			name: "discovered check",
+			setup: func(pos *Position) {
+				pos.SetPiece('R', "e2")
+				pos.SetPiece('N', "e4")
+			},
+			move:     "e4c5",
+			expected: true,
+		},
+		{
+			name: "blocker moving along the line",
+			setup: func(pos *Position) {
+				pos.SetPiece('R', "e2")
+				pos.SetPiece('R', "e4")
+			},
+			move:     "e4e5",
+			expected: true, // direct check from the rook on e5
+		},
+		{
+			name: "blocker moving along the line without discovering",
+			setup: func(pos *Position) {
+				pos.SetPiece('R', "e2")
+				pos.SetPiece('P', "e4")
+			},
+			move:     "e4e5",
+			expected: false,
+		},
+		{
+			name: "promotion check",
+			setup: func(pos *Position) {
+				pos.SetPiece('P', "b7")
+			},
+			move:     "b7b8q",
+			expected: true,
+		},
+		{
+			name: "under promotion without check",
+			setup: func(pos *Position) {
+				pos.SetPiece('P', "b7")
+			},
+			move:     "b7b8b",
+			expected: false,
+		},
+		{
+			name: "knight promotion check",
+			setup: func(pos *Position) {
+				pos.SetPiece('P', "f7")
+				pos.SetPiece('k', "d7")
+			},
+			move:     "f7f8n",
+			expected: true,
+		},
+		{
+			name: "knight promotion without check",
+			setup: func(pos *Position) {
+				pos.SetPiece('P', "f7")
+			},
+			move:     "f7f8n",
+			expected: false,
+		},
+		{
+			name: "promotion check through the vacated square",
+			setup: func(pos *Position) {
+				pos.SetPiece('P', "a7")
+				pos.SetPiece('k', "a6")
+			},
+			move:     "a7a8r",
+			expected: true,
+		},
+		{
+			name: "en passant discovered check",
+			setup: func(pos *Position) {
+				pos.SetPiece('R', "a5")
+				pos.SetPiece('P', "c5")
+				pos.SetPiece('p', "d5")
+				pos.SetPiece('k', "h5")
+				pos.SetEnPassantTarget("d6")
+			},
+			move:     "c5d6",
+			expected: true,
+		},
+		{
+			name: "castling rook check",
+			setup: func(pos *Position) {
+				pos.SetPiece('K', "e1")
+				pos.SetPiece('R', "h1")
+				pos.SetPiece('k', "f8")
+			},
+			move:     "e1g1",
+			expected: true,
+		},
+		{
+			name: "long castling rook check",
+			setup: func(pos *Position) {
+				pos.SetPiece('K', "e1")
+				pos.SetPiece('R', "a1")
+				pos.SetPiece('k', "d8")
+			},
+			move:     "e1c1",
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := Position{}
+			tt.setup(&pos)
+			if pos.BlackKing == 0 {
+				pos.SetPiece('k', "e8")
+			}
+			if pos.WhiteKing == 0 {
+				pos.SetPiece('K', "h1")
+			}
+			pos.SideToMove = "white"
+			pos.UpdateState()
+
+			got := pos.GivesCheck(move(tt.move))
+			if got != tt.expected {
+				t.Errorf("expected GivesCheck(%s) to be %v", tt.move, tt.expected)
+			}
+		})
+	}
+}
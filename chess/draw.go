@@ -0,0 +1,36 @@
+package chess
+
+// IsDraw reports whether the position is drawn by the fifty move rule or by
+// repetition. ply is the number of moves made since the search started: a
+// repetition of a position reached after the search root counts as a draw
+// straight away, since either side could have avoided it, while one of an
+// earlier position needs to be the third occurrence. Only the last
+// HalfmoveClock plies can repeat, and the scan stops at a null move, as
+// the positions before it weren't reached by moves.
+func (p *Position) IsDraw(ply int) bool {
+	if p.HalfmoveClock >= 100 && (!p.InCheck() || len(p.GenerateLegalMoves()) > 0) {
+		return true
+	}
+
+	repetitions := 0
+	end := min(p.HalfmoveClock, len(p.history))
+	for back := 1; back <= end; back++ {
+		undo := &p.history[len(p.history)-back]
+		if undo.Null {
+			return false
+		}
+		// undo.Hash is the hash back plies ago; only positions with the
+		// same side to move can repeat this one
+		if back%2 != 0 || undo.Hash != p.Hash {
+			continue
+		}
+		if back < ply {
+			return true
+		}
+		repetitions++
+		if repetitions == 2 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package chess
+
+import "testing"
+
+func shuffleKnights(pos *Position) {
+	for _, move := range []string{"g1f3", "g8f6", "f3g1", "f6g8"} {
+		pos.ApplyMove(move)
+	}
+}
+
+func TestIsDrawRepetition(t *testing.T) {
+	pos := NewPosition()
+	shuffleKnights(pos)
+
+	// the start position has occurred twice, both before the search
+	if pos.IsDraw(0) {
+		t.Errorf("expected a single repetition before the root not to be a draw")
+	}
+	// but a repetition inside the search is enough
+	if !pos.IsDraw(5) {
+		t.Errorf("expected a repetition after the root to be a draw")
+	}
+
+	shuffleKnights(pos)
+	if !pos.IsDraw(0) {
+		t.Errorf("expected the third occurrence to be a draw")
+	}
+}
+
+func TestIsDrawStopsAtIrreversibleMoves(t *testing.T) {
+	pos := NewPosition()
+	shuffleKnights(pos)
+	pos.ApplyMove("e2e3")
+	pos.ApplyMove("e7e6")
+	shuffleKnights(pos)
+
+	// the start position is behind the pawn moves and can't repeat
+	if pos.IsDraw(0) {
+		t.Errorf("expected no draw after a single repetition since the pawn moves")
+	}
+	shuffleKnights(pos)
+	if !pos.IsDraw(0) {
+		t.Errorf("expected the third occurrence since the pawn moves to be a draw")
+	}
+}
+
+func TestIsDrawStopsAtNullMove(t *testing.T) {
+	pos := NewPosition()
+	pos.ApplyMove("g1f3")
+	pos.MakeNullMove()
+	pos.ApplyMove("f3g1")
+	pos.MakeNullMove()
+
+	// the start position again, but only by passing twice
+	if pos.Hash != NewPosition().Hash {
+		t.Fatalf("expected the start position to be reached again")
+	}
+	if pos.IsDraw(10) {
+		t.Errorf("expected positions before a null move not to count as repetitions")
+	}
+}
+
+func TestIsDrawFiftyMoveRule(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		want bool
+	}{
+		{"clock at 99", "4k3/8/8/8/8/8/8/R3K3 w - - 99 80", false},
+		{"clock at 100", "4k3/8/8/8/8/8/8/R3K3 w - - 100 80", true},
+		{"in check", "4k3/8/8/8/8/8/8/4RK2 b - - 100 80", true},
+		{"checkmate", "R3k3/8/4K3/8/8/8/8/8 b - - 100 80", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := mustParseFEN(t, tt.fen)
+			if got := pos.IsDraw(0); got != tt.want {
+				t.Errorf("IsDraw = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
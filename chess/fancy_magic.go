@@ -0,0 +1,74 @@
+package chess
+
+import "fmt"
+
+// Magic packs everything needed to look up the attacks of a slider on one
+// square. Offset is where the square's attacks start in the shared
+// magicAttackTable array.
+type Magic struct {
+	Mask   Bitboard
+	Number uint64
+	Shift  uint
+	Offset uint32
+}
+
+// Index returns the position of the attacks for the given occupancy in
+// magicAttackTable
+func (m *Magic) Index(occupancy Bitboard) uint32 {
+	return m.Offset + uint32(uint64(occupancy&m.Mask)*m.Number>>m.Shift)
+}
+
+// Fancy magic tables: the attacks of every rook and bishop square are laid
+// out back to back in one array rather than one slice per square, so a
+// lookup is a single index into contiguous memory.
+var RookMagicEntries, BishopMagicEntries, magicAttackTable = buildSliderMagics()
+
+func buildSliderMagics() ([64]Magic, [64]Magic, []Bitboard) {
+	var rooks, bishops [64]Magic
+	var attacks []Bitboard
+
+	fill := func(entries *[64]Magic, magics *[64]uint64, indexBits *[64]int, relevantMask func(int) Bitboard, computeAttacks func(int, Bitboard) Bitboard) {
+		for sq := 0; sq < 64; sq++ {
+			mask := relevantMask(sq)
+
+			entries[sq] = Magic{
+				Mask:   mask,
+				Number: magics[sq],
+				Shift:  uint(64 - indexBits[sq]),
+				Offset: uint32(len(attacks)),
+			}
+			attacks = append(attacks, make([]Bitboard, 1<<indexBits[sq])...)
+
+			for _, occupancy := range GenerateOccupancyVariations(mask) {
+				attacks[entries[sq].Index(occupancy)] = computeAttacks(sq, occupancy)
+			}
+		}
+	}
+
+	fill(&rooks, &RookMagics, &RookMagicBits, RookRelevantMask, ComputeRookAttacks)
+	fill(&bishops, &BishopMagics, &BishopMagicBits, BishopRelevantMask, ComputeBishopAttacks)
+
+	if err := verifySliderMagics(&rooks, attacks, ComputeRookAttacks); err != nil {
+		panic(err)
+	}
+	if err := verifySliderMagics(&bishops, attacks, ComputeBishopAttacks); err != nil {
+		panic(err)
+	}
+
+	return rooks, bishops, attacks
+}
+
+// verifySliderMagics checks every occupancy of every square against the
+// ray walk, so a bad magic fails at startup rather than in a search
+func verifySliderMagics(entries *[64]Magic, attacks []Bitboard, computeAttacks func(int, Bitboard) Bitboard) error {
+	for sq := 0; sq < 64; sq++ {
+		m := &entries[sq]
+		for _, occupancy := range GenerateOccupancyVariations(m.Mask) {
+			if attacks[m.Index(occupancy)] != computeAttacks(sq, occupancy) {
+				return fmt.Errorf("magic for %s gives wrong attacks for occupancy %#x",
+					BitIndexToRankFile(sq), uint64(occupancy))
+			}
+		}
+	}
+	return nil
+}
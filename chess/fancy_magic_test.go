@@ -0,0 +1,106 @@
+package chess
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// random occupancies with roughly a quarter of the squares filled
+func randomOccupancies(n int) []Bitboard {
+	r := rand.New(rand.NewSource(1))
+	occupancies := make([]Bitboard, n)
+	for i := range occupancies {
+		occupancies[i] = Bitboard(r.Uint64() & r.Uint64())
+	}
+	return occupancies
+}
+
+func TestBishopRelevantMask(t *testing.T) {
+	tests := []struct {
+		square   string
+		expected Bitboard
+	}{
+		{"a1", squares("b2", "c3", "d4", "e5", "f6", "g7")},
+		{"d4", squares("c3", "b2", "e5", "f6", "g7", "c5", "b6", "e3", "f2")},
+		{"h5", squares("g6", "f7", "g4", "f3", "e2")},
+	}
+
+	for _, tt := range tests {
+		mask := BishopRelevantMask(square(tt.square))
+		if mask != tt.expected {
+			t.Errorf("%s: expected: \n%sgot: \n%s", tt.square, ToString(tt.expected), ToString(mask))
+		}
+	}
+}
+
+func TestFancyMagicsMatchRayAttacks(t *testing.T) {
+	if err := verifySliderMagics(&RookMagicEntries, magicAttackTable, ComputeRookAttacks); err != nil {
+		t.Error(err)
+	}
+	if err := verifySliderMagics(&BishopMagicEntries, magicAttackTable, ComputeBishopAttacks); err != nil {
+		t.Error(err)
+	}
+
+	// unlike the variations above, these include squares outside the masks
+	for _, occupancy := range randomOccupancies(1000) {
+		for sq := 0; sq < 64; sq++ {
+			if RookAttacks(sq, occupancy) != ComputeRookAttacks(sq, occupancy) {
+				t.Fatalf("%s: rook attacks differ for occupancy %#x", BitIndexToRankFile(sq), uint64(occupancy))
+			}
+			if BishopAttacks(sq, occupancy) != ComputeBishopAttacks(sq, occupancy) {
+				t.Fatalf("%s: bishop attacks differ for occupancy %#x", BitIndexToRankFile(sq), uint64(occupancy))
+			}
+		}
+	}
+}
+
+func TestSliderAttacksSize(t *testing.T) {
+	expected := 0
+	for sq := 0; sq < 64; sq++ {
+		expected += 1<<RookMagicBits[sq] + 1<<BishopMagicBits[sq]
+	}
+
+	// at most 102,400 rook entries plus 5,248 bishop entries, fewer if
+	// some magics use less than the relevant bits
+	if len(magicAttackTable) != expected || expected > 102400+5248 {
+		t.Errorf("unexpected attack table size %d", len(magicAttackTable))
+	}
+}
+
+var benchmarkSink Bitboard
+
+// BenchmarkRookAttacksMagicIndex looks attacks up through MagicIndex and the
+// per-square RookAttackTables, for comparison with the fancy layout
+func BenchmarkRookAttacksMagicIndex(b *testing.B) {
+	occupancies := randomOccupancies(1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sq := i & 63
+		idx := MagicIndex(sq, occupancies[i&1023], RookMasks[sq], RookMagics[sq], RookRelevantBitsMap[sq])
+		benchmarkSink ^= RookAttackTables[sq][idx]
+	}
+}
+
+func BenchmarkRookAttacksFancy(b *testing.B) {
+	occupancies := randomOccupancies(1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchmarkSink ^= RookAttacks(i&63, occupancies[i&1023])
+	}
+}
+
+func BenchmarkBishopAttacksRayWalk(b *testing.B) {
+	occupancies := randomOccupancies(1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchmarkSink ^= ComputeBishopAttacks(i&63, occupancies[i&1023])
+	}
+}
+
+func BenchmarkBishopAttacksFancy(b *testing.B) {
+	occupancies := randomOccupancies(1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchmarkSink ^= BishopAttacks(i&63, occupancies[i&1023])
+	}
+}
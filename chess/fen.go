@@ -0,0 +1,95 @@
+package chess
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const StartFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// NewPositionFromFEN sets up a position from Forsyth-Edwards Notation. The
+// halfmove clock and fullmove number may be left off.
+func NewPositionFromFEN(fen string) (*Position, error) {
+	fields := strings.Fields(fen)
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("invalid FEN %q: expected at least 4 fields", fen)
+	}
+
+	pos := &Position{FullmoveNumber: 1}
+
+	ranks := strings.Split(fields[0], "/")
+	if len(ranks) != 8 {
+		return nil, fmt.Errorf("invalid FEN %q: expected 8 ranks", fen)
+	}
+	for i, rank := range ranks {
+		file := 0
+		for j := 0; j < len(rank); j++ {
+			c := rank[j]
+			if c >= '1' && c <= '8' {
+				file += int(c - '0')
+				continue
+			}
+			if !strings.ContainsRune("PNBRQKpnbrqk", rune(c)) || file > 7 {
+				return nil, fmt.Errorf("invalid FEN %q: bad piece placement", fen)
+			}
+			pos.putPiece(c, (7-i)*8+file)
+			file++
+		}
+		if file != 8 {
+			return nil, fmt.Errorf("invalid FEN %q: rank %d doesn't have 8 files", fen, 8-i)
+		}
+	}
+
+	switch fields[1] {
+	case "w":
+		pos.SideToMove = "white"
+	case "b":
+		pos.SideToMove = "black"
+	default:
+		return nil, fmt.Errorf("invalid FEN %q: bad side to move", fen)
+	}
+
+	if fields[2] != "-" {
+		for _, c := range fields[2] {
+			switch c {
+			case 'K':
+				pos.WhiteCastlingRights.Short = true
+			case 'Q':
+				pos.WhiteCastlingRights.Long = true
+			case 'k':
+				pos.BlackCastlingRights.Short = true
+			case 'q':
+				pos.BlackCastlingRights.Long = true
+			default:
+				return nil, fmt.Errorf("invalid FEN %q: bad castling rights", fen)
+			}
+		}
+	}
+
+	if fields[3] != "-" {
+		ep := fields[3]
+		if len(ep) != 2 || ep[0] < 'a' || ep[0] > 'h' || (ep[1] != '3' && ep[1] != '6') {
+			return nil, fmt.Errorf("invalid FEN %q: bad en passant square", fen)
+		}
+		pos.SetEnPassantTarget(ep)
+	}
+
+	if len(fields) >= 6 {
+		halfmove, err := strconv.Atoi(fields[4])
+		if err != nil {
+			return nil, fmt.Errorf("invalid FEN %q: bad halfmove clock", fen)
+		}
+		fullmove, err := strconv.Atoi(fields[5])
+		if err != nil {
+			return nil, fmt.Errorf("invalid FEN %q: bad fullmove number", fen)
+		}
+		pos.HalfmoveClock = halfmove
+		pos.FullmoveNumber = fullmove
+	}
+
+	pos.Hash = pos.ComputeHash()
+	pos.UpdateState()
+
+	return pos, nil
+}
@@ -0,0 +1,54 @@
+package chess
+
+import "testing"
+
+func TestNewPositionFromFEN_StartPosition(t *testing.T) {
+	pos := mustParseFEN(t, StartFEN)
+
+	if !samePosition(*pos, *NewPosition()) {
+		t.Errorf("expected the start FEN to match NewPosition")
+	}
+}
+
+func TestNewPositionFromFEN(t *testing.T) {
+	pos := mustParseFEN(t, "r3k2r/8/8/3pP3/8/8/8/4K2R w Kq d6 3 42")
+
+	assertHasPiece(t, pos, 'r', "a8")
+	assertHasPiece(t, pos, 'k', "e8")
+	assertHasPiece(t, pos, 'p', "d5")
+	assertHasPiece(t, pos, 'P', "e5")
+	assertHasPiece(t, pos, 'R', "h1")
+	assertEmpty(t, pos, "a1")
+
+	if pos.SideToMove != "white" {
+		t.Errorf("expected white to move")
+	}
+	if pos.WhiteCastlingRights != (CastlingRights{Short: true}) {
+		t.Errorf("unexpected white castling rights %+v", pos.WhiteCastlingRights)
+	}
+	if pos.BlackCastlingRights != (CastlingRights{Long: true}) {
+		t.Errorf("unexpected black castling rights %+v", pos.BlackCastlingRights)
+	}
+	if pos.EnPassantTarget != squares("d6") {
+		t.Errorf("expected en passant target on d6")
+	}
+	if pos.HalfmoveClock != 3 || pos.FullmoveNumber != 42 {
+		t.Errorf("expected clocks 3/42, got %d/%d", pos.HalfmoveClock, pos.FullmoveNumber)
+	}
+}
+
+func TestNewPositionFromFEN_Invalid(t *testing.T) {
+	for _, fen := range []string{
+		"",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP w KQkq -",
+		"rnbqkbnr/pppppppp/9/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNX w KQkq -",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq -",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkx -",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq e5",
+	} {
+		if _, err := NewPositionFromFEN(fen); err == nil {
+			t.Errorf("expected an error for %q", fen)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package chess
+
+// IsPseudoLegal reports whether move follows the movement rules of the piece
+// on its origin square in the current position, without generating every
+// move. It is meant for moves that may be stale, e.g. moves coming from a
+// hash table, killer slots or a GUI, and doesn't check whether the move
+// leaves the mover's king in check; see IsLegal for that.
+func (p *Position) IsPseudoLegal(move Move) bool {
+	if move.From < 0 || move.From > 63 || move.To < 0 || move.To > 63 || move.From == move.To {
+		return false
+	}
+
+	piece := p.PieceMap[move.From]
+	us := p.ColorToMove()
+	if piece == 0 || PieceColor(piece) != us {
+		return false
+	}
+
+	toMask := Bitboard(1) << move.To
+	friendly := p.PiecesOfColor(us)
+	enemy := p.PiecesOfColor(us ^ 1)
+	occupancy := friendly | enemy
+
+	if friendly&toMask != 0 {
+		return false
+	}
+
+	pieceType := PieceType(piece)
+	if pieceType != Pawn {
+		if move.Promo != 0 {
+			return false
+		}
+		if pieceType == King && Abs(move.To-move.From) == 2 {
+			return p.isPseudoLegalCastling(move, us, occupancy)
+		}
+		return PieceAttacks(pieceType, move.From, occupancy)&toMask != 0
+	}
+
+	// pawns must promote when reaching the last rank, and only then
+	promotionRank := Rank_8
+	forward := 8
+	startRank := 1
+	if us == Black {
+		promotionRank = Rank_1
+		forward = -8
+		startRank = 6
+	}
+
+	if promotionRank&toMask != 0 {
+		switch ToUpper(move.Promo) {
+		case 'Q', 'R', 'B', 'N':
+		default:
+			return false
+		}
+	} else if move.Promo != 0 {
+		return false
+	}
+
+	switch {
+	case move.To == move.From+forward:
+		return occupancy&toMask == 0
+	case move.To == move.From+2*forward:
+		return move.From/8 == startRank && occupancy&toMask == 0 &&
+			occupancy&(Bitboard(1)<<(move.From+forward)) == 0
+	default:
+		return PawnAttacks[us][move.From]&toMask&(enemy|p.EnPassantTarget) != 0
+	}
+}
+
+func (p *Position) isPseudoLegalCastling(move Move, us int, occupancy Bitboard) bool {
+	rights := p.WhiteCastlingRights
+	kingFrom := 4
+	if us == Black {
+		rights = p.BlackCastlingRights
+		kingFrom = 60
+	}
+
+	switch move.To {
+	case kingFrom + 2:
+		return rights.Short && p.canCastle(us, kingFrom, move.To, occupancy)
+	case kingFrom - 2:
+		return rights.Long && p.canCastle(us, kingFrom, move.To, occupancy)
+	}
+	return false
+}
+
+// IsLegal reports whether a pseudo-legal move leaves the mover's king safe.
+// It relies on the cached checkers and pins in p.State, so the result is
+// only meaningful for moves that passed IsPseudoLegal or came from
+// GenerateMoves.
+func (p *Position) IsLegal(move Move) bool {
+	us := p.ColorToMove()
+	them := us ^ 1
+	ksq := p.KingSquare(us)
+	if ksq == NoSquare {
+		return true
+	}
+
+	fromMask := Bitboard(1) << move.From
+	toMask := Bitboard(1) << move.To
+	occupancy := p.GetOccupiedSquares()
+	enemy := p.PiecesOfColor(them)
+	pieceType := PieceType(p.PieceMap[move.From])
+
+	if pieceType == King {
+		if Abs(move.To-move.From) == 2 {
+			// can't castle out of, through or into check
+			if p.InCheck() {
+				return false
+			}
+			step := 1
+			if move.To < move.From {
+				step = -1
+			}
+			for sq := move.From + step; sq != move.To+step; sq += step {
+				if p.AttackersTo(sq, occupancy)&enemy != 0 {
+					return false
+				}
+			}
+			return true
+		}
+
+		// the king must not be attacked on its new square. It is removed
+		// from the occupancy so it can't hide behind itself from a slider.
+		return p.AttackersTo(move.To, occupancy&^fromMask)&enemy&^toMask == 0
+	}
+
+	if pieceType == Pawn && toMask == p.EnPassantTarget {
+		// both pawns leave their rank, which can expose the king to a slider
+		// that was previously blocked by two pieces
+		captured := move.To - 8
+		if us == Black {
+			captured = move.To + 8
+		}
+		capturedMask := Bitboard(1) << captured
+		occupancy = occupancy&^fromMask&^capturedMask | toMask
+
+		return p.AttackersTo(ksq, occupancy)&enemy&^capturedMask == 0
+	}
+
+	if checkers := p.State.Checkers; checkers != 0 {
+		// a double check can only be answered by a king move
+		if checkers&(checkers-1) != 0 {
+			return false
+		}
+		// otherwise the move has to capture the checker or block the check
+		checker := PopLSB(&checkers)
+		if (Between[ksq][checker]|(Bitboard(1)<<checker))&toMask == 0 {
+			return false
+		}
+	}
+
+	// a pinned piece may only move along the line of the pin
+	return p.State.Pinned[us]&fromMask == 0 || Aligned(move.From, move.To, ksq)
+}
@@ -0,0 +1,254 @@
+package chess
+
+import "testing"
+
+// builds a handful of positions with pins, checks, en passant, castling
+// and promotions to cross check IsPseudoLegal against GenerateMoves
+func legalityTestPositions() []*Position {
+	positions := []*Position{NewPosition()}
+
+	pos := &Position{
+		WhiteCastlingRights: CastlingRights{Short: true, Long: true},
+		BlackCastlingRights: CastlingRights{Short: true, Long: true},
+	}
+	for _, pc := range []struct {
+		piece  byte
+		square string
+	}{
+		{'K', "e1"}, {'R', "a1"}, {'R', "h1"}, {'P', "e5"}, {'P', "b7"}, {'N', "c3"}, {'B', "g2"}, {'Q', "d1"},
+		{'k', "e8"}, {'r', "a8"}, {'r', "h8"}, {'p', "d5"}, {'p', "g3"}, {'n', "f6"}, {'b', "b4"}, {'q', "d8"},
+	} {
+		pos.SetPiece(pc.piece, pc.square)
+	}
+	pos.SetEnPassantTarget("d6")
+	pos.SideToMove = "white"
+	pos.UpdateState()
+	positions = append(positions, pos)
+
+	black := *pos
+	black.SideToMove = "black"
+	black.EnPassantTarget = 0
+	black.UpdateState()
+	positions = append(positions, &black)
+
+	return positions
+}
+
+func TestIsPseudoLegalMatchesGenerateMoves(t *testing.T) {
+	for i, pos := range legalityTestPositions() {
+		generated := map[Move]bool{}
+		for _, m := range pos.GenerateMoves() {
+			generated[m] = true
+		}
+
+		for from := 0; from < 64; from++ {
+			for to := 0; to < 64; to++ {
+				for _, promo := range []byte{0, 'q', 'r', 'b', 'n', 'k'} {
+					m := Move{From: from, To: to, Promo: promo}
+					if pos.IsPseudoLegal(m) != generated[m] {
+						t.Errorf("position %d: IsPseudoLegal(%s%c) = %v, but generated = %v",
+							i, ToUCINotation(m), promo, pos.IsPseudoLegal(m), generated[m])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestIsPseudoLegal_StaleMoves(t *testing.T) {
+	pos := NewPosition()
+
+	tests := []struct {
+		move     string
+		expected bool
+	}{
+		{"e2e4", true},
+		{"g1f3", true},
+		{"e2e5", false},  // too far
+		{"e7e5", false},  // wrong side
+		{"f1c4", false},  // blocked by own pawn
+		{"e1g1", false},  // pieces in the way
+		{"e4e5", false},  // empty square
+		{"a1a3", false},  // blocked by own pawn
+		{"e2e3q", false}, // not a promotion square
+	}
+
+	for _, tt := range tests {
+		if got := pos.IsPseudoLegal(move(tt.move)); got != tt.expected {
+			t.Errorf("expected IsPseudoLegal(%s) to be %v", tt.move, tt.expected)
+		}
+	}
+}
+
+func TestIsLegal(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(pos *Position)
+		move     string
+		expected bool
+	}{
+		{
+			name: "pinned piece leaving the pin",
+			setup: func(pos *Position) {
+				pos.SetPiece('R', "e4")
+				pos.SetPiece('r', "e8")
+			},
+			move:     "e4d4",
+			expected: false,
+		},
+		{
+			name: "pinned piece moving along the pin",
+			setup: func(pos *Position) {
+				pos.SetPiece('R', "e4")
+				pos.SetPiece('r', "e8")
+			},
+			move:     "e4e8",
+			expected: true,
+		},
+		{
+			name: "king moving into check",
+			setup: func(pos *Position) {
+				pos.SetPiece('r', "d8")
+			},
+			move:     "e1d1",
+			expected: false,
+		},
+		{
+			name: "king stepping back along the checking line",
+			setup: func(pos *Position) {
+				pos.SetPiece('r', "e8")
+			},
+			move:     "e1e2",
+			expected: false,
+		},
+		{
+			name: "king capturing a protected piece",
+			setup: func(pos *Position) {
+				pos.SetPiece('p', "e2")
+				pos.SetPiece('n', "g3")
+			},
+			move:     "e1e2",
+			expected: false,
+		},
+		{
+			name: "not answering a check",
+			setup: func(pos *Position) {
+				pos.SetPiece('r', "e8")
+				pos.SetPiece('P', "a2")
+			},
+			move:     "a2a3",
+			expected: false,
+		},
+		{
+			name: "blocking a check",
+			setup: func(pos *Position) {
+				pos.SetPiece('r', "e8")
+				pos.SetPiece('R', "a4")
+			},
+			move:     "a4e4",
+			expected: true,
+		},
+		{
+			name: "capturing the checker",
+			setup: func(pos *Position) {
+				pos.SetPiece('n', "f3")
+				pos.SetPiece('P', "g2")
+			},
+			move:     "g2f3",
+			expected: true,
+		},
+		{
+			name: "blocking a double check",
+			setup: func(pos *Position) {
+				pos.SetPiece('r', "e8")
+				pos.SetPiece('n', "d3")
+				pos.SetPiece('R', "a4")
+			},
+			move:     "a4e4",
+			expected: false,
+		},
+		{
+			name: "en passant exposing the king on the rank",
+			setup: func(pos *Position) {
+				pos.RemovePiece("e1")
+				pos.SetPiece('K', "a5")
+				pos.SetPiece('P', "d5")
+				pos.SetPiece('p', "e5")
+				pos.SetPiece('r', "h5")
+				pos.SetEnPassantTarget("e6")
+			},
+			move:     "d5e6",
+			expected: false,
+		},
+		{
+			name: "en passant capturing the checking pawn",
+			setup: func(pos *Position) {
+				pos.RemovePiece("e1")
+				pos.SetPiece('K', "f4")
+				pos.SetPiece('P', "d5")
+				pos.SetPiece('p', "e5")
+				pos.SetEnPassantTarget("e6")
+			},
+			move:     "d5e6",
+			expected: true,
+		},
+		{
+			name: "castling",
+			setup: func(pos *Position) {
+				pos.SetPiece('R', "h1")
+				pos.WhiteCastlingRights.Short = true
+			},
+			move:     "e1g1",
+			expected: true,
+		},
+		{
+			name: "castling through check",
+			setup: func(pos *Position) {
+				pos.SetPiece('R', "h1")
+				pos.SetPiece('r', "f8")
+				pos.WhiteCastlingRights.Short = true
+			},
+			move:     "e1g1",
+			expected: false,
+		},
+		{
+			name: "castling out of check",
+			setup: func(pos *Position) {
+				pos.SetPiece('R', "a1")
+				pos.SetPiece('b', "b4")
+				pos.WhiteCastlingRights.Long = true
+			},
+			move:     "e1c1",
+			expected: false,
+		},
+		{
+			name: "long castling with b1 attacked",
+			setup: func(pos *Position) {
+				pos.SetPiece('R', "a1")
+				pos.SetPiece('r', "b8")
+				pos.WhiteCastlingRights.Long = true
+			},
+			move:     "e1c1",
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := Position{}
+			pos.SetPiece('K', "e1")
+			pos.SetPiece('k', "a8")
+			tt.setup(&pos)
+			pos.SideToMove = "white"
+			pos.UpdateState()
+
+			m := move(tt.move)
+			if !pos.IsPseudoLegal(m) {
+				t.Fatalf("expected %s to be pseudo-legal", tt.move)
+			}
+			if got := pos.IsLegal(m); got != tt.expected {
+				t.Errorf("expected IsLegal(%s) to be %v", tt.move, tt.expected)
+			}
+		})
+	}
+}
@@ -2,7 +2,6 @@ package chess
 
 import (
 	"math/bits"
-	"math/rand"
 )
 
 const (
@@ -111,67 +110,49 @@ func ComputeRookAttacks(square int, occupancy Bitboard) Bitboard {
 	return attacks
 }
 
-func GenerateRookAttackTable() [64][]Bitboard {
-	var table [64][]Bitboard
+func ComputeBishopAttacks(square int, occupancy Bitboard) Bitboard {
+	startRank := square / 8
+	startFile := square % 8
 
-	for sq := 0; sq < 64; sq++ {
-		mask := RookRelevantMask(sq)
-		variations := GenerateOccupancyVariations(mask)
+	attacks := Bitboard(0)
 
-		var attacksForSquare []Bitboard
-		for _, occupancy := range variations {
-			attacksForSquare = append(
-				attacksForSquare,
-				ComputeRookAttacks(sq, occupancy),
-			)
+	// north east
+	for rank, file := startRank+1, startFile+1; rank < 8 && file < 8; rank, file = rank+1, file+1 {
+		attackIdx := (rank * 8) + file
+		attacks |= Bitboard(1) << Bitboard(attackIdx)
+		if occupancy&(1<<attackIdx) != 0 {
+			break
 		}
-		table[sq] = attacksForSquare
 	}
 
-	return table
-}
-
-func sparseRandUint64() uint64 {
-	return rand.Uint64() & rand.Uint64() & rand.Uint64()
-}
+	// north west
+	for rank, file := startRank+1, startFile-1; rank < 8 && file >= 0; rank, file = rank+1, file-1 {
+		attackIdx := (rank * 8) + file
+		attacks |= Bitboard(1) << Bitboard(attackIdx)
+		if occupancy&(1<<attackIdx) != 0 {
+			break
+		}
+	}
 
-// Take the square and its relevant mask.
-// Loop over random candidate 64-bit numbers.
-// For each candidate:
-// Compute indices for all occupancy variations: (occupancy * candidate) >> (64 - relevantBits).
-// Check if all indices are unique (no collisions).
-// Return the first candidate that works.
-func FindRookMagic(square int, relevantBits uint) uint64 {
-	mask := RookRelevantMask(square)
-	variations := GenerateOccupancyVariations(mask)
-
-	var candidate uint64
-	for attempts := 0; attempts < 10000000; attempts++ {
-		candidate = sparseRandUint64()
-
-		table := make(map[uint64]Bitboard)
-		success := true
-
-		for _, occupancy := range variations {
-			index := uint64((occupancy * Bitboard(candidate)) >> (64 - relevantBits))
-			attacks := ComputeRookAttacks(square, occupancy)
-
-			if existing, ok := table[index]; ok {
-				// Collision: two different occupancies map to same index
-				if existing != attacks {
-					success = false
-					break
-				}
-			} else {
-				table[index] = attacks
-			}
+	// south east
+	for rank, file := startRank-1, startFile+1; rank >= 0 && file < 8; rank, file = rank-1, file+1 {
+		attackIdx := (rank * 8) + file
+		attacks |= Bitboard(1) << Bitboard(attackIdx)
+		if occupancy&(1<<attackIdx) != 0 {
+			break
 		}
+	}
 
-		if success {
-			return candidate
+	// south west
+	for rank, file := startRank-1, startFile-1; rank >= 0 && file >= 0; rank, file = rank-1, file-1 {
+		attackIdx := (rank * 8) + file
+		attacks |= Bitboard(1) << Bitboard(attackIdx)
+		if occupancy&(1<<attackIdx) != 0 {
+			break
 		}
 	}
-	panic("failed to find magic number")
+
+	return attacks
 }
 
 func PopCount(bb Bitboard) int {
@@ -188,28 +169,17 @@ func RookRelevantBits(square int) uint {
 	return uint(PopCount(mask))
 }
 
-func GenerateRookMagic(reportProgress func()) ([64]uint64, [64][]Bitboard) {
-	var magics [64]uint64
-	var attackTables [64][]Bitboard
-
-	for square := 0; square < 64; square++ {
-		relevantBits := RookRelevantBits(square)
-		magics[square] = FindRookMagic(square, relevantBits)
-
-		size := 1 << relevantBits
-		attackTables[square] = make([]Bitboard, size)
-
-		mask := RookRelevantMask(square)
-		variations := GenerateOccupancyVariations(mask)
-
-		for _, occupancy := range variations {
-			index := (uint64(occupancy) * magics[square]) >> (64 - relevantBits)
-			attackTables[square][index] = ComputeRookAttacks(square, occupancy)
-		}
-		reportProgress()
-	}
+// BishopRelevantMask returns the squares on the diagonals of square that
+// can block a bishop. The board edges never block anything further, so they
+// are left out.
+func BishopRelevantMask(square int) Bitboard {
+	edges := A_File | H_File | Rank_1 | Rank_8
+	return ComputeBishopAttacks(square, 0) &^ edges
+}
 
-	return magics, attackTables
+func BishopRelevantBits(square int) uint {
+	mask := BishopRelevantMask(square)
+	return uint(PopCount(mask))
 }
 
 // Given a square and occupancy, compute the magic index for a rook or bishop
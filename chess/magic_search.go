@@ -0,0 +1,207 @@
+package chess
+
+import (
+	"math/bits"
+	"math/rand"
+	"runtime"
+	"sync"
+)
+
+// MagicSearchOptions configures GenerateMagics
+type MagicSearchOptions struct {
+	// Seed makes the search reproducible. Each square derives its own
+	// random source from it, so the result doesn't depend on Workers or
+	// on goroutine scheduling.
+	Seed int64
+
+	// number of goroutines searching squares concurrently, defaults to
+	// runtime.NumCPU()
+	Workers int
+
+	// MaxAttempts bounds the number of candidates tried for each tighter
+	// table size when ReduceBits is set, defaults to maxMagicAttempts
+	MaxAttempts int
+
+	// ReduceBits asks for magics that index a table with up to this many
+	// bits fewer than the relevant mask. Each square keeps the smallest
+	// table found within MaxAttempts and falls back to the full size.
+	ReduceBits int
+}
+
+// the number of candidates tried before giving up on a square
+const maxMagicAttempts = 10000000
+
+// MagicResult holds a magic number for every square together with the
+// number of index bits it was found for
+type MagicResult struct {
+	Magics [64]uint64
+	Bits   [64]int
+}
+
+// magicSearch finds magics for one square. The attacks for every occupancy
+// are computed once up front, and collisions are detected with an epoch
+// stamped table that is reused between candidates instead of allocating a
+// map for each one.
+type magicSearch struct {
+	mask        Bitboard
+	occupancies []Bitboard
+	attacks     []Bitboard
+
+	table []Bitboard
+	epoch []uint32
+	now   uint32
+}
+
+func newMagicSearch(square int, mask Bitboard, computeAttacks func(int, Bitboard) Bitboard) *magicSearch {
+	occupancies := GenerateOccupancyVariations(mask)
+	attacks := make([]Bitboard, len(occupancies))
+	for i, occupancy := range occupancies {
+		attacks[i] = computeAttacks(square, occupancy)
+	}
+
+	return &magicSearch{
+		mask:        mask,
+		occupancies: occupancies,
+		attacks:     attacks,
+		table:       make([]Bitboard, len(occupancies)),
+		epoch:       make([]uint32, len(occupancies)),
+	}
+}
+
+// try reports whether candidate maps every occupancy to an index in a
+// table of 1<<indexBits entries without a destructive collision, i.e. two
+// occupancies with different attacks sharing an index
+func (s *magicSearch) try(candidate uint64, indexBits int) bool {
+	s.now++
+	if s.now == 0 {
+		// the epoch wrapped around, so old stamps could look current
+		clear(s.epoch)
+		s.now = 1
+	}
+	shift := 64 - indexBits
+
+	for i, occupancy := range s.occupancies {
+		index := uint64(occupancy) * candidate >> shift
+		if s.epoch[index] != s.now {
+			s.epoch[index] = s.now
+			s.table[index] = s.attacks[i]
+		} else if s.table[index] != s.attacks[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *magicSearch) find(r *rand.Rand, indexBits, maxAttempts int) (uint64, bool) {
+	for attempts := 0; attempts < maxAttempts; attempts++ {
+		candidate := r.Uint64() & r.Uint64() & r.Uint64()
+
+		// a good magic spreads the mask over the top bits of the product,
+		// so skip candidates that leave the top byte mostly empty
+		if bits.OnesCount64(uint64(s.mask)*candidate>>56) < 6 {
+			continue
+		}
+
+		if s.try(candidate, indexBits) {
+			return candidate, true
+		}
+	}
+	return 0, false
+}
+
+// squareRand returns the random source for one square of a search
+func squareRand(seed int64, square int) *rand.Rand {
+	return rand.New(rand.NewSource(seed ^ int64(uint64(square+1)*0x9E3779B97F4A7C15)))
+}
+
+// GenerateMagics searches magic numbers for every square for rooks or
+// bishops (pieceType is Rook or Bishop), spreading the squares over
+// several goroutines. reportProgress, if not nil, is called once per
+// finished square from the calling goroutine.
+func GenerateMagics(pieceType int, opts MagicSearchOptions, reportProgress func()) MagicResult {
+	relevantMask, computeAttacks := RookRelevantMask, ComputeRookAttacks
+	if pieceType == Bishop {
+		relevantMask, computeAttacks = BishopRelevantMask, ComputeBishopAttacks
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = maxMagicAttempts
+	}
+
+	var result MagicResult
+	squares := make(chan int)
+	done := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for sq := range squares {
+				mask := relevantMask(sq)
+				search := newMagicSearch(sq, mask, computeAttacks)
+				r := squareRand(opts.Seed, sq)
+
+				relevant := PopCount(mask)
+				indexBits := relevant
+				magic, ok := search.find(r, indexBits, maxMagicAttempts)
+				if !ok {
+					panic("failed to find magic number")
+				}
+
+				for reduction := 1; reduction <= opts.ReduceBits; reduction++ {
+					tighter, ok := search.find(r, relevant-reduction, opts.MaxAttempts)
+					if !ok {
+						break
+					}
+					magic, indexBits = tighter, relevant-reduction
+				}
+
+				// each worker writes to its own squares only
+				result.Magics[sq] = magic
+				result.Bits[sq] = indexBits
+				done <- sq
+			}
+		}()
+	}
+
+	go func() {
+		for sq := 0; sq < 64; sq++ {
+			squares <- sq
+		}
+		close(squares)
+		wg.Wait()
+		close(done)
+	}()
+
+	for range done {
+		if reportProgress != nil {
+			reportProgress()
+		}
+	}
+
+	return result
+}
+
+// FindRookMagic returns a magic number for a rook on square that indexes a
+// table of 1<<relevantBits entries. The search is seeded by the square, so
+// it always returns the same number.
+func FindRookMagic(square int, relevantBits uint) uint64 {
+	search := newMagicSearch(square, RookRelevantMask(square), ComputeRookAttacks)
+	magic, ok := search.find(squareRand(0, square), int(relevantBits), maxMagicAttempts)
+	if !ok {
+		panic("failed to find magic number")
+	}
+	return magic
+}
+
+func FindBishopMagic(square int, relevantBits uint) uint64 {
+	search := newMagicSearch(square, BishopRelevantMask(square), ComputeBishopAttacks)
+	magic, ok := search.find(squareRand(0, square), int(relevantBits), maxMagicAttempts)
+	if !ok {
+		panic("failed to find magic number")
+	}
+	return magic
+}
@@ -0,0 +1,77 @@
+package chess
+
+import "testing"
+
+// checks that every magic in the result indexes its table without
+// destructive collisions
+func verifyMagicResult(t *testing.T, result MagicResult, relevantMask func(int) Bitboard, computeAttacks func(int, Bitboard) Bitboard) {
+	t.Helper()
+	for sq := 0; sq < 64; sq++ {
+		search := newMagicSearch(sq, relevantMask(sq), computeAttacks)
+		if result.Bits[sq] > PopCount(relevantMask(sq)) {
+			t.Errorf("%s: %d index bits is more than the mask needs", BitIndexToRankFile(sq), result.Bits[sq])
+		}
+		if !search.try(result.Magics[sq], result.Bits[sq]) {
+			t.Errorf("%s: magic %#x has collisions", BitIndexToRankFile(sq), result.Magics[sq])
+		}
+	}
+}
+
+func TestGenerateMagicsIsReproducible(t *testing.T) {
+	progress := 0
+	single := GenerateMagics(Bishop, MagicSearchOptions{Seed: 42, Workers: 1}, func() { progress++ })
+	parallel := GenerateMagics(Bishop, MagicSearchOptions{Seed: 42, Workers: 8}, nil)
+
+	if single != parallel {
+		t.Errorf("expected the same seed to give the same magics regardless of the number of workers")
+	}
+	if progress != 64 {
+		t.Errorf("expected progress to be reported 64 times, got %d", progress)
+	}
+
+	other := GenerateMagics(Bishop, MagicSearchOptions{Seed: 43}, nil)
+	if other.Magics == single.Magics {
+		t.Errorf("expected a different seed to give different magics")
+	}
+
+	verifyMagicResult(t, single, BishopRelevantMask, ComputeBishopAttacks)
+	verifyMagicResult(t, other, BishopRelevantMask, ComputeBishopAttacks)
+}
+
+func TestGenerateRookMagics(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping rook magic search in short mode")
+	}
+
+	result := GenerateMagics(Rook, MagicSearchOptions{Seed: 1}, nil)
+	verifyMagicResult(t, result, RookRelevantMask, ComputeRookAttacks)
+}
+
+func TestGenerateMagicsReduceBits(t *testing.T) {
+	opts := MagicSearchOptions{Seed: 7, ReduceBits: 2, MaxAttempts: 20000}
+	result := GenerateMagics(Bishop, opts, nil)
+
+	verifyMagicResult(t, result, BishopRelevantMask, ComputeBishopAttacks)
+	for sq := 0; sq < 64; sq++ {
+		if result.Bits[sq] < PopCount(BishopRelevantMask(sq))-2 {
+			t.Errorf("%s: reduced by more than 2 bits", BitIndexToRankFile(sq))
+		}
+	}
+}
+
+func TestMagicSearchDetectsCollisions(t *testing.T) {
+	sq := square("d4")
+	search := newMagicSearch(sq, RookRelevantMask(sq), ComputeRookAttacks)
+
+	if !search.try(RookMagics[sq], RookMagicBits[sq]) {
+		t.Errorf("expected the stored magic to be accepted")
+	}
+	// a magic of 1 maps the mask onto itself, so the top bits of the
+	// product can't tell occupancies apart
+	if search.try(1, RookMagicBits[sq]) {
+		t.Errorf("expected a magic of 1 to collide")
+	}
+	if search.try(RookMagics[sq], 4) {
+		t.Errorf("expected 4 index bits to be too few for a rook on d4")
+	}
+}
@@ -0,0 +1,48 @@
+package chess
+
+import "fmt"
+
+// The per-square rook masks, relevant bit counts and attack tables built
+// from RookMagics. The engine looks attacks up in the fancy magic layout,
+// see fancy_magic.go, so these only live in the tests, which check the
+// magics through them and compare lookup speed with the fancy layout.
+var RookMasks, RookRelevantBitsMap, RookAttackTables = buildRookTables()
+
+func buildRookTables() ([64]Bitboard, [64]int, [64][]Bitboard) {
+	var masks [64]Bitboard
+	var relevantBits [64]int
+	var attackTables [64][]Bitboard
+
+	for sq := 0; sq < 64; sq++ {
+		masks[sq] = RookRelevantMask(sq)
+		relevantBits[sq] = PopCount(masks[sq])
+		attackTables[sq] = make([]Bitboard, 1<<relevantBits[sq])
+
+		for _, occupancy := range GenerateOccupancyVariations(masks[sq]) {
+			idx := MagicIndex(sq, occupancy, masks[sq], RookMagics[sq], relevantBits[sq])
+			attackTables[sq][idx] = ComputeRookAttacks(sq, occupancy)
+		}
+	}
+
+	if err := verifyRookTables(masks, relevantBits, attackTables); err != nil {
+		panic(err)
+	}
+
+	return masks, relevantBits, attackTables
+}
+
+// verifyRookTables looks up every occupancy of every square and checks the
+// result against the ray walk in ComputeRookAttacks. A mismatch means two
+// occupancies with different attacks share an index, i.e. a bad magic.
+func verifyRookTables(masks [64]Bitboard, relevantBits [64]int, attackTables [64][]Bitboard) error {
+	for sq := 0; sq < 64; sq++ {
+		for _, occupancy := range GenerateOccupancyVariations(masks[sq]) {
+			idx := MagicIndex(sq, occupancy, masks[sq], RookMagics[sq], relevantBits[sq])
+			if attackTables[sq][idx] != ComputeRookAttacks(sq, occupancy) {
+				return fmt.Errorf("rook magic for %s gives wrong attacks for occupancy %#x",
+					BitIndexToRankFile(sq), uint64(occupancy))
+			}
+		}
+	}
+	return nil
+}
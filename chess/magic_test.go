@@ -159,3 +159,31 @@ func TestMagicIndex(t *testing.T) {
 		t.Errorf("expected hex: %#x, actual: %#x", expected, actual)
 	}
 }
+
+func TestRookTablesMatchRayAttacks(t *testing.T) {
+	if err := verifyRookTables(RookMasks, RookRelevantBitsMap, RookAttackTables); err != nil {
+		t.Error(err)
+	}
+
+	for sq := 0; sq < 64; sq++ {
+		if RookMasks[sq] != RookRelevantMask(sq) {
+			t.Errorf("%s: mask doesn't match RookRelevantMask", BitIndexToRankFile(sq))
+		}
+		if len(RookAttackTables[sq]) != 1<<RookRelevantBitsMap[sq] {
+			t.Errorf("%s: expected %d table entries, got %d",
+				BitIndexToRankFile(sq), 1<<RookRelevantBitsMap[sq], len(RookAttackTables[sq]))
+		}
+	}
+}
+
+func TestVerifyRookTablesDetectsBadMagic(t *testing.T) {
+	masks, relevantBits, tables := RookMasks, RookRelevantBitsMap, RookAttackTables
+
+	// corrupt one entry of a copy of the a1 table
+	tables[0] = append([]Bitboard(nil), tables[0]...)
+	tables[0][0] ^= 1 << 8
+
+	if err := verifyRookTables(masks, relevantBits, tables); err == nil {
+		t.Errorf("expected a corrupted table to fail verification")
+	}
+}
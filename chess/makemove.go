@@ -0,0 +1,201 @@
+package chess
+
+// Undo records everything MakeMove and MakeNullMove change that can't be
+// worked out again from the move itself, so UnmakeMove can restore it.
+// A null move is recorded with Null set and an empty Move.
+type Undo struct {
+	Move     Move
+	Null     bool
+	Captured byte
+
+	WhiteCastlingRights CastlingRights
+	BlackCastlingRights CastlingRights
+	EnPassantTarget     Bitboard
+	HalfmoveClock       int
+	Hash                uint64
+	State               StateInfo
+}
+
+// putPiece, takePiece and movePiece keep the bitboards, PieceMap and hash
+// in sync for a single square
+func (p *Position) putPiece(piece byte, square int) {
+	*p.pieceBitboard(piece) |= Bitboard(1) << square
+	p.PieceMap[square] = piece
+	p.Hash ^= pieceKey(piece, square)
+}
+
+func (p *Position) takePiece(square int) byte {
+	piece := p.PieceMap[square]
+	*p.pieceBitboard(piece) &^= Bitboard(1) << square
+	p.PieceMap[square] = 0
+	p.Hash ^= pieceKey(piece, square)
+	return piece
+}
+
+func (p *Position) movePiece(from, to int) {
+	p.putPiece(p.takePiece(from), to)
+}
+
+// enPassantCaptureSquare returns the square of the pawn removed when a pawn
+// of the given color captures en passant on to
+func enPassantCaptureSquare(color, to int) int {
+	if color == White {
+		return to - 8
+	}
+	return to + 8
+}
+
+// MakeMove plays move on the board, which is assumed to be pseudo-legal,
+// and pushes an Undo record onto the position's history so that the move
+// can be taken back with UnmakeMove.
+func (p *Position) MakeMove(move Move) {
+	p.history = append(p.history, p.makeMove(move))
+}
+
+// makeMove plays the move and returns the Undo record for it, leaving the
+// history alone
+func (p *Position) makeMove(move Move) Undo {
+	undo := Undo{
+		Move:                move,
+		WhiteCastlingRights: p.WhiteCastlingRights,
+		BlackCastlingRights: p.BlackCastlingRights,
+		EnPassantTarget:     p.EnPassantTarget,
+		HalfmoveClock:       p.HalfmoveClock,
+		Hash:                p.Hash,
+		State:               p.State,
+	}
+
+	piece := p.PieceMap[move.From]
+	color := PieceColor(piece)
+	pieceType := PieceType(piece)
+	toMask := Bitboard(1) << move.To
+
+	p.Hash ^= p.castlingKey() ^ p.enPassantKey()
+
+	p.HalfmoveClock++
+	if pieceType == Pawn {
+		p.HalfmoveClock = 0
+	}
+
+	captureSquare := move.To
+	if pieceType == Pawn && toMask == p.EnPassantTarget {
+		captureSquare = enPassantCaptureSquare(color, move.To)
+	}
+	if p.PieceMap[captureSquare] != 0 {
+		undo.Captured = p.takePiece(captureSquare)
+		p.HalfmoveClock = 0
+	}
+
+	if move.Promo != 0 {
+		p.takePiece(move.From)
+		promotion := move.Promo
+		if color == White {
+			promotion = ToUpper(promotion)
+		} else {
+			promotion = toLower(promotion)
+		}
+		p.putPiece(promotion, move.To)
+	} else {
+		p.movePiece(move.From, move.To)
+	}
+
+	if pieceType == King && Abs(move.To-move.From) == 2 {
+		rookFrom, rookTo := castlingRookSquares(move.To)
+		p.movePiece(rookFrom, rookTo)
+	}
+
+	p.updateCastlingRights(move.To, move.From)
+	p.updateEnpassantState(piece, move.To, move.From)
+	p.Hash ^= p.castlingKey() ^ p.enPassantKey()
+
+	if color == Black {
+		p.FullmoveNumber++
+	}
+	p.changeTurn()
+	p.Hash ^= zobristSideToMove
+
+	p.UpdateState()
+
+	return undo
+}
+
+// UnmakeMove takes back the last move made with MakeMove
+func (p *Position) UnmakeMove() {
+	undo := p.history[len(p.history)-1]
+	p.history = p.history[:len(p.history)-1]
+	move := undo.Move
+
+	p.changeTurn()
+	color := p.ColorToMove()
+	if color == Black {
+		p.FullmoveNumber--
+	}
+
+	piece := p.PieceMap[move.To]
+	if PieceType(piece) == King && Abs(move.To-move.From) == 2 {
+		rookFrom, rookTo := castlingRookSquares(move.To)
+		p.movePiece(rookTo, rookFrom)
+	}
+
+	if move.Promo != 0 {
+		p.takePiece(move.To)
+		if color == White {
+			p.putPiece('P', move.From)
+		} else {
+			p.putPiece('p', move.From)
+		}
+	} else {
+		p.movePiece(move.To, move.From)
+	}
+
+	if undo.Captured != 0 {
+		captureSquare := move.To
+		if PieceType(p.PieceMap[move.From]) == Pawn && Bitboard(1)<<move.To == undo.EnPassantTarget {
+			captureSquare = enPassantCaptureSquare(color, move.To)
+		}
+		p.putPiece(undo.Captured, captureSquare)
+	}
+
+	p.restore(undo)
+}
+
+// MakeNullMove passes the turn to the opponent without moving a piece, as
+// used by null move pruning. It must not be played while in check.
+func (p *Position) MakeNullMove() {
+	p.history = append(p.history, Undo{
+		Null:                true,
+		WhiteCastlingRights: p.WhiteCastlingRights,
+		BlackCastlingRights: p.BlackCastlingRights,
+		EnPassantTarget:     p.EnPassantTarget,
+		HalfmoveClock:       p.HalfmoveClock,
+		Hash:                p.Hash,
+		State:               p.State,
+	})
+
+	p.Hash ^= p.enPassantKey()
+	p.EnPassantTarget = 0
+	p.HalfmoveClock++
+
+	p.changeTurn()
+	p.Hash ^= zobristSideToMove
+
+	p.UpdateState()
+}
+
+// UnmakeNullMove takes back the last move made with MakeNullMove
+func (p *Position) UnmakeNullMove() {
+	undo := p.history[len(p.history)-1]
+	p.history = p.history[:len(p.history)-1]
+
+	p.changeTurn()
+	p.restore(undo)
+}
+
+func (p *Position) restore(undo Undo) {
+	p.WhiteCastlingRights = undo.WhiteCastlingRights
+	p.BlackCastlingRights = undo.BlackCastlingRights
+	p.EnPassantTarget = undo.EnPassantTarget
+	p.HalfmoveClock = undo.HalfmoveClock
+	p.Hash = undo.Hash
+	p.State = undo.State
+}
@@ -0,0 +1,161 @@
+package chess
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+const (
+	kiwipeteFEN = "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"
+	position3   = "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1"
+	position4   = "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1"
+	position5   = "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8"
+)
+
+// compares two positions, ignoring their move history
+func samePosition(a, b Position) bool {
+	a.history = nil
+	b.history = nil
+	return reflect.DeepEqual(a, b)
+}
+
+func mustParseFEN(t testing.TB, fen string) *Position {
+	t.Helper()
+	pos, err := NewPositionFromFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pos
+}
+
+func TestPerft(t *testing.T) {
+	tests := []struct {
+		fen    string
+		depth  int
+		expect uint64
+	}{
+		{StartFEN, 1, 20},
+		{StartFEN, 2, 400},
+		{StartFEN, 3, 8902},
+		{StartFEN, 4, 197281},
+		{kiwipeteFEN, 1, 48},
+		{kiwipeteFEN, 2, 2039},
+		{kiwipeteFEN, 3, 97862},
+		{position3, 4, 43238},
+		{position4, 3, 9467},
+		{position5, 3, 62379},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s depth %d", tt.fen, tt.depth), func(t *testing.T) {
+			if testing.Short() && tt.expect > 10000 {
+				t.Skip("skipping deep perft in short mode")
+			}
+			pos := mustParseFEN(t, tt.fen)
+			if got := pos.Perft(tt.depth); got != tt.expect {
+				t.Errorf("expected %d nodes, got %d", tt.expect, got)
+			}
+		})
+	}
+}
+
+// walks the move tree checking that the incremental hash matches a hash
+// computed from scratch, and that unmaking a move restores the position
+func checkMakeUnmake(t *testing.T, pos *Position, depth int) {
+	t.Helper()
+	if depth == 0 {
+		return
+	}
+
+	for _, m := range pos.GenerateLegalMoves() {
+		before := *pos
+
+		pos.MakeMove(m)
+		if pos.Hash != pos.ComputeHash() {
+			t.Fatalf("incremental hash doesn't match after %s", ToUCINotation(m))
+		}
+		checkMakeUnmake(t, pos, depth-1)
+		pos.UnmakeMove()
+
+		if !samePosition(*pos, before) {
+			t.Fatalf("position not restored after unmaking %s", ToUCINotation(m))
+		}
+	}
+}
+
+func TestMakeUnmakeRestoresPosition(t *testing.T) {
+	for _, fen := range []string{StartFEN, kiwipeteFEN, position3, position4, position5} {
+		checkMakeUnmake(t, mustParseFEN(t, fen), 2)
+	}
+}
+
+func TestMakeMoveCounters(t *testing.T) {
+	pos := NewPosition()
+
+	pos.ApplyMove("g1f3")
+	if pos.HalfmoveClock != 1 || pos.FullmoveNumber != 1 {
+		t.Errorf("expected clocks 1/1, got %d/%d", pos.HalfmoveClock, pos.FullmoveNumber)
+	}
+
+	pos.ApplyMove("b8c6")
+	if pos.HalfmoveClock != 2 || pos.FullmoveNumber != 2 {
+		t.Errorf("expected clocks 2/2, got %d/%d", pos.HalfmoveClock, pos.FullmoveNumber)
+	}
+
+	pos.ApplyMove("e2e4")
+	if pos.HalfmoveClock != 0 {
+		t.Errorf("expected a pawn move to reset the halfmove clock, got %d", pos.HalfmoveClock)
+	}
+}
+
+func TestNullMove(t *testing.T) {
+	pos := NewPosition()
+	pos.ApplyMove("e2e4")
+
+	before := *pos
+
+	pos.MakeNullMove()
+
+	if pos.SideToMove != "white" {
+		t.Errorf("expected the null move to pass the turn back to white")
+	}
+	if pos.EnPassantTarget != 0 {
+		t.Errorf("expected the null move to clear the en passant target")
+	}
+	if pos.Hash != pos.ComputeHash() {
+		t.Errorf("hash not updated by the null move")
+	}
+	if pos.Hash == before.Hash {
+		t.Errorf("expected the hash to change after a null move")
+	}
+	// check squares are from the point of view of the side to move
+	if pos.State.CheckSquares[Knight] != KnightAttacks[square("e8")] {
+		t.Errorf("expected state to be recomputed for white to move")
+	}
+
+	pos.UnmakeNullMove()
+
+	if !samePosition(*pos, before) {
+		t.Errorf("position not restored after unmaking the null move")
+	}
+}
+
+func TestNullMoveBetweenMoves(t *testing.T) {
+	pos := NewPosition()
+
+	pos.ApplyMove("e2e4")
+	pos.MakeNullMove()
+	pos.ApplyMove("d2d4")
+	if pos.PieceMap[square("d4")] != 'P' || pos.SideToMove != "black" {
+		t.Fatalf("expected white to move again after the null move")
+	}
+
+	pos.UnmakeMove()
+	pos.UnmakeNullMove()
+	pos.UnmakeMove()
+
+	if !samePosition(*pos, *NewPosition()) {
+		t.Errorf("expected to be back at the start position")
+	}
+}
@@ -0,0 +1,147 @@
+package chess
+
+// promotion pieces in the order they are generated, using lower case to be
+// consistent with UCI notation
+var promotionPieces = [4]byte{'q', 'r', 'b', 'n'}
+
+// GenerateMoves returns every pseudo-legal move for the side to move:
+// moves that follow the movement rules of each piece but might leave the
+// mover's own king in check. Use IsLegal or GenerateLegalMoves to filter
+// those out.
+func (p *Position) GenerateMoves() []Move {
+	moves := make([]Move, 0, 64)
+
+	us := p.ColorToMove()
+	friendly := p.PiecesOfColor(us)
+	enemy := p.PiecesOfColor(us ^ 1)
+	occupancy := friendly | enemy
+
+	moves = p.appendPawnMoves(moves, us, enemy, occupancy)
+
+	for pieceType := Knight; pieceType <= King; pieceType++ {
+		for pieces := p.PiecesOfType(us, pieceType); pieces != 0; {
+			from := PopLSB(&pieces)
+			targets := PieceAttacks(pieceType, from, occupancy) &^ friendly
+			for targets != 0 {
+				moves = append(moves, Move{From: from, To: PopLSB(&targets)})
+			}
+		}
+	}
+
+	return p.appendCastlingMoves(moves, us, occupancy)
+}
+
+// GenerateLegalMoves returns every legal move for the side to move
+func (p *Position) GenerateLegalMoves() []Move {
+	moves := p.GenerateMoves()
+
+	legal := moves[:0]
+	for _, m := range moves {
+		if p.IsLegal(m) {
+			legal = append(legal, m)
+		}
+	}
+
+	return legal
+}
+
+// PieceAttacks returns the squares attacked by a knight, bishop, rook, queen
+// or king on square. Pawns are direction dependent, see PawnAttacks.
+func PieceAttacks(pieceType, square int, occupancy Bitboard) Bitboard {
+	switch pieceType {
+	case Knight:
+		return KnightAttacks[square]
+	case Bishop:
+		return BishopAttacks(square, occupancy)
+	case Rook:
+		return RookAttacks(square, occupancy)
+	case Queen:
+		return QueenAttacks(square, occupancy)
+	case King:
+		return KingAttacks[square]
+	}
+	panic("unexpected piece type")
+}
+
+func (p *Position) appendPawnMoves(moves []Move, us int, enemy, occupancy Bitboard) []Move {
+	pawns := p.PiecesOfType(us, Pawn)
+	empty := ^occupancy
+
+	// the rank pawns promote on and the rank they start from, as seen from
+	// the side to move
+	promotionRank := Rank_8
+	startRank := Bitboard(0x000000000000FF00)
+	forward := 8
+	if us == Black {
+		promotionRank = Rank_1
+		startRank = Bitboard(0x00FF000000000000)
+		forward = -8
+	}
+
+	for bb := pawns; bb != 0; {
+		from := PopLSB(&bb)
+		to := from + forward
+
+		if empty&(Bitboard(1)<<to) != 0 {
+			moves = appendPawnMove(moves, from, to, promotionRank)
+
+			doublePush := to + forward
+			if startRank&(Bitboard(1)<<from) != 0 && empty&(Bitboard(1)<<doublePush) != 0 {
+				moves = append(moves, Move{From: from, To: doublePush})
+			}
+		}
+
+		captures := PawnAttacks[us][from] & (enemy | p.EnPassantTarget)
+		for captures != 0 {
+			moves = appendPawnMove(moves, from, PopLSB(&captures), promotionRank)
+		}
+	}
+
+	return moves
+}
+
+func appendPawnMove(moves []Move, from, to int, promotionRank Bitboard) []Move {
+	if promotionRank&(Bitboard(1)<<to) == 0 {
+		return append(moves, Move{From: from, To: to})
+	}
+
+	for _, promo := range promotionPieces {
+		moves = append(moves, Move{From: from, To: to, Promo: promo})
+	}
+	return moves
+}
+
+// appendCastlingMoves adds castling moves whose path between king and rook
+// is clear. Whether the king passes through check is left to IsLegal.
+func (p *Position) appendCastlingMoves(moves []Move, us int, occupancy Bitboard) []Move {
+	kingFrom := 4
+	rights := p.WhiteCastlingRights
+	if us == Black {
+		kingFrom = 60
+		rights = p.BlackCastlingRights
+	}
+
+	if rights.Short && p.canCastle(us, kingFrom, kingFrom+2, occupancy) {
+		moves = append(moves, Move{From: kingFrom, To: kingFrom + 2})
+	}
+	if rights.Long && p.canCastle(us, kingFrom, kingFrom-2, occupancy) {
+		moves = append(moves, Move{From: kingFrom, To: kingFrom - 2})
+	}
+
+	return moves
+}
+
+// canCastle checks that the king and rook are on their starting squares and
+// nothing stands between them
+func (p *Position) canCastle(us, kingFrom, kingTo int, occupancy Bitboard) bool {
+	rookFrom, _ := castlingRookSquares(kingTo)
+
+	if p.PiecesOfType(us, King)&(Bitboard(1)<<kingFrom) == 0 {
+		return false
+	}
+	if p.PiecesOfType(us, Rook)&(Bitboard(1)<<rookFrom) == 0 {
+		return false
+	}
+
+	return Between[kingFrom][rookFrom]&occupancy == 0
+}
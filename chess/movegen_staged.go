@@ -0,0 +1,121 @@
+package chess
+
+// GenerateCaptures and GenerateQuiets split GenerateMoves into two stages,
+// so a search can look at the captures first and skip generating the
+// quiet moves entirely when a capture causes a cutoff, or when it only
+// wants captures, as in a quiescence search. Together they return the
+// same moves as GenerateMoves.
+//
+// Promotions to a queen count as captures even when they don't take
+// anything since they win material just the same, while under-promotions
+// are almost never worth searching early and count as quiet moves.
+
+// GenerateCaptures returns the pseudo-legal captures, including en passant,
+// and queen promotions for the side to move
+func (p *Position) GenerateCaptures() []Move {
+	moves := make([]Move, 0, 32)
+
+	us := p.ColorToMove()
+	friendly := p.PiecesOfColor(us)
+	enemy := p.PiecesOfColor(us ^ 1)
+	occupancy := friendly | enemy
+
+	promotionRank, forward := pawnDirection(us)
+	for pawns := p.PiecesOfType(us, Pawn); pawns != 0; {
+		from := PopLSB(&pawns)
+
+		if to := from + forward; promotionRank&(Bitboard(1)<<to) != 0 && occupancy&(Bitboard(1)<<to) == 0 {
+			moves = append(moves, Move{From: from, To: to, Promo: 'q'})
+		}
+
+		captures := PawnAttacks[us][from] & (enemy | p.EnPassantTarget)
+		for captures != 0 {
+			to := PopLSB(&captures)
+			if promotionRank&(Bitboard(1)<<to) != 0 {
+				moves = append(moves, Move{From: from, To: to, Promo: 'q'})
+			} else {
+				moves = append(moves, Move{From: from, To: to})
+			}
+		}
+	}
+
+	for pieceType := Knight; pieceType <= King; pieceType++ {
+		for pieces := p.PiecesOfType(us, pieceType); pieces != 0; {
+			from := PopLSB(&pieces)
+			targets := PieceAttacks(pieceType, from, occupancy) & enemy
+			for targets != 0 {
+				moves = append(moves, Move{From: from, To: PopLSB(&targets)})
+			}
+		}
+	}
+
+	return moves
+}
+
+// GenerateQuiets returns the pseudo-legal moves that GenerateCaptures
+// leaves out: moves to empty squares, castling and under-promotions
+func (p *Position) GenerateQuiets() []Move {
+	moves := make([]Move, 0, 48)
+
+	us := p.ColorToMove()
+	friendly := p.PiecesOfColor(us)
+	enemy := p.PiecesOfColor(us ^ 1)
+	occupancy := friendly | enemy
+	empty := ^occupancy
+
+	promotionRank, forward := pawnDirection(us)
+	startRank := Bitboard(0x000000000000FF00)
+	if us == Black {
+		startRank = Bitboard(0x00FF000000000000)
+	}
+
+	for pawns := p.PiecesOfType(us, Pawn); pawns != 0; {
+		from := PopLSB(&pawns)
+		to := from + forward
+
+		if empty&(Bitboard(1)<<to) != 0 {
+			if promotionRank&(Bitboard(1)<<to) != 0 {
+				moves = appendUnderPromotions(moves, from, to)
+			} else {
+				moves = append(moves, Move{From: from, To: to})
+			}
+
+			doublePush := to + forward
+			if startRank&(Bitboard(1)<<from) != 0 && empty&(Bitboard(1)<<doublePush) != 0 {
+				moves = append(moves, Move{From: from, To: doublePush})
+			}
+		}
+
+		for captures := PawnAttacks[us][from] & enemy & promotionRank; captures != 0; {
+			moves = appendUnderPromotions(moves, from, PopLSB(&captures))
+		}
+	}
+
+	for pieceType := Knight; pieceType <= King; pieceType++ {
+		for pieces := p.PiecesOfType(us, pieceType); pieces != 0; {
+			from := PopLSB(&pieces)
+			targets := PieceAttacks(pieceType, from, occupancy) & empty
+			for targets != 0 {
+				moves = append(moves, Move{From: from, To: PopLSB(&targets)})
+			}
+		}
+	}
+
+	return p.appendCastlingMoves(moves, us, occupancy)
+}
+
+// pawnDirection returns the rank pawns of the given color promote on and
+// the square offset of a single push
+func pawnDirection(color int) (promotionRank Bitboard, forward int) {
+	if color == Black {
+		return Rank_1, -8
+	}
+	return Rank_8, 8
+}
+
+func appendUnderPromotions(moves []Move, from, to int) []Move {
+	for _, promo := range promotionPieces[1:] {
+		moves = append(moves, Move{From: from, To: to, Promo: promo})
+	}
+	return moves
+}
@@ -0,0 +1,67 @@
+package chess
+
+import (
+	"sort"
+	"testing"
+)
+
+func sortedUCIMoves(moves []Move) []string {
+	uci := make([]string, len(moves))
+	for i, m := range moves {
+		uci[i] = ToUCINotation(m)
+	}
+	sort.Strings(uci)
+	return uci
+}
+
+// checkStagedMoves compares the two stages with GenerateMoves in pos and in
+// every position reachable within depth plies
+func checkStagedMoves(t *testing.T, pos *Position, depth int) {
+	t.Helper()
+
+	captures := pos.GenerateCaptures()
+	for _, m := range captures {
+		isCapture := pos.PieceMap[m.To] != 0 ||
+			PieceType(pos.PieceMap[m.From]) == Pawn && Bitboard(1)<<m.To == pos.EnPassantTarget
+		if !isCapture && m.Promo != 'q' {
+			t.Fatalf("%s is neither a capture nor a queen promotion", ToUCINotation(m))
+		}
+	}
+
+	staged := sortedUCIMoves(append(captures, pos.GenerateQuiets()...))
+	all := sortedUCIMoves(pos.GenerateMoves())
+	if len(staged) != len(all) {
+		t.Fatalf("expected the stages to have %d moves, got %d: %v vs %v", len(all), len(staged), staged, all)
+	}
+	for i := range all {
+		if staged[i] != all[i] {
+			t.Fatalf("expected the stages to match GenerateMoves: %v vs %v", staged, all)
+		}
+	}
+
+	if depth == 0 {
+		return
+	}
+	for _, m := range pos.GenerateLegalMoves() {
+		pos.MakeMove(m)
+		checkStagedMoves(t, pos, depth-1)
+		pos.UnmakeMove()
+	}
+}
+
+func TestStagedMoveGeneration(t *testing.T) {
+	for _, fen := range []string{StartFEN, kiwipeteFEN, position3, position4, position5} {
+		checkStagedMoves(t, mustParseFEN(t, fen), 2)
+	}
+}
+
+func TestGenerateCaptures_Promotions(t *testing.T) {
+	pos := mustParseFEN(t, "1n2k3/P7/8/8/8/8/8/4K3 w - - 0 1")
+
+	assertEqualMoves(t, pos.GenerateCaptures(), map[string]bool{"a7a8q": true, "a7b8q": true})
+	assertEqualMoves(t, pos.GenerateQuiets(), map[string]bool{
+		"a7a8r": true, "a7a8b": true, "a7a8n": true,
+		"a7b8r": true, "a7b8b": true, "a7b8n": true,
+		"e1d1": true, "e1f1": true, "e1d2": true, "e1e2": true, "e1f2": true,
+	})
+}
@@ -0,0 +1,108 @@
+package chess
+
+import "testing"
+
+func TestGenerateMoves_StartPosition(t *testing.T) {
+	pos := NewPosition()
+
+	moves := pos.GenerateMoves()
+	if len(moves) != 20 {
+		t.Errorf("expected 20 moves from the start position but got %d", len(moves))
+	}
+
+	pos.SideToMove = "black"
+	moves = pos.GenerateMoves()
+	if len(moves) != 20 {
+		t.Errorf("expected 20 black moves from the start position but got %d", len(moves))
+	}
+}
+
+func TestGenerateMoves_Pieces(t *testing.T) {
+	pos := Position{}
+	pos.SetPiece('N', "b1")
+	pos.SetPiece('B', "c1")
+	pos.SetPiece('P', "b2")
+	pos.SetPiece('P', "d2")
+	pos.SetPiece('Q', "h1")
+	pos.SetPiece('p', "h3")
+	pos.SideToMove = "white"
+
+	expected := map[string]bool{
+		// knight
+		"b1a3": true, "b1c3": true,
+		// the bishop is boxed in by its own pawns
+		// queen, stopping at the pawn on h3
+		"h1h2": true, "h1h3": true,
+		"h1g1": true, "h1f1": true, "h1e1": true, "h1d1": true,
+		"h1g2": true, "h1f3": true, "h1e4": true, "h1d5": true, "h1c6": true, "h1b7": true, "h1a8": true,
+		// pawns
+		"b2b3": true, "b2b4": true, "d2d3": true, "d2d4": true,
+	}
+
+	assertEqualMoves(t, pos.GenerateMoves(), expected)
+}
+
+func TestGenerateMoves_Promotions(t *testing.T) {
+	pos := Position{}
+	pos.SetPiece('p', "g2")
+	pos.SetPiece('R', "h1")
+	pos.SetPiece('N', "g1")
+	pos.SideToMove = "black"
+
+	expected := map[string]bool{
+		"g2h1q": true, "g2h1r": true, "g2h1b": true, "g2h1n": true,
+	}
+
+	assertEqualMoves(t, pos.GenerateMoves(), expected)
+}
+
+func TestGenerateMoves_Castling(t *testing.T) {
+	pos := Position{
+		WhiteCastlingRights: CastlingRights{Short: true, Long: true},
+	}
+	pos.SetPiece('K', "e1")
+	pos.SetPiece('R', "a1")
+	pos.SetPiece('R', "h1")
+	pos.SideToMove = "white"
+
+	castles := map[string]bool{}
+	for _, m := range pos.GenerateMoves() {
+		if m.From == square("e1") && Abs(m.To-m.From) == 2 {
+			castles[ToUCINotation(m)] = true
+		}
+	}
+
+	if !castles["e1g1"] || !castles["e1c1"] || len(castles) != 2 {
+		t.Errorf("expected both castling moves, got %v", castles)
+	}
+
+	// a piece between king and rook prevents castling on that side
+	pos.SetPiece('N', "b1")
+	pos.WhiteCastlingRights.Short = false
+	for _, m := range pos.GenerateMoves() {
+		if m.From == square("e1") && Abs(m.To-m.From) == 2 {
+			t.Errorf("unexpected castling move %s", ToUCINotation(m))
+		}
+	}
+}
+
+func TestGenerateLegalMoves(t *testing.T) {
+	// the white king is in check from the rook on e8, the knight on e2 is
+	// pinned and can't block, but the bishop can
+	pos := Position{}
+	pos.SetPiece('K', "e1")
+	pos.SetPiece('N', "d2")
+	pos.SetPiece('B', "c4")
+	pos.SetPiece('r', "e8")
+	pos.SetPiece('b', "a5")
+	pos.SetPiece('k', "a8")
+	pos.SideToMove = "white"
+	pos.UpdateState()
+
+	expected := map[string]bool{
+		"e1f1": true, "e1f2": true, "e1d1": true,
+		"c4e6": true, "c4e2": true,
+	}
+
+	assertEqualMoves(t, pos.GenerateLegalMoves(), expected)
+}
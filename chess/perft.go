@@ -0,0 +1,23 @@
+package chess
+
+// Perft counts the leaf nodes of the legal move tree to the given depth.
+// Comparing the counts with published values is the standard way of
+// checking move generation and make/unmake.
+func (p *Position) Perft(depth int) uint64 {
+	moves := p.GenerateLegalMoves()
+	if depth <= 1 {
+		if depth <= 0 {
+			return 1
+		}
+		return uint64(len(moves))
+	}
+
+	nodes := uint64(0)
+	for _, m := range moves {
+		p.MakeMove(m)
+		nodes += p.Perft(depth - 1)
+		p.UnmakeMove()
+	}
+
+	return nodes
+}
@@ -18,8 +18,7 @@ func (p *Position) GenerateRookMoves() []Move {
 
 	for bb := rooks; bb != 0; {
 		from := PopLSB(&bb)
-		idx := MagicIndex(from, occupancy, RookMasks[from], RookMagics[from], RookRelevantBitsMap[from])
-		attacks := RookAttackTables[from][idx]
+		attacks := RookAttacks(from, occupancy)
 		attacks &^= friendly
 
 		for a := attacks; a != 0; {
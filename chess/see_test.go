@@ -0,0 +1,29 @@
+package chess
+
+import "testing"
+
+func TestSEE(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		move     string
+		expected int
+	}{
+		{"undefended pawn", "1k1r4/1pp4p/p7/4p3/8/P5P1/1PP4P/2K1R3 w - - 0 1", "e1e5", 100},
+		{"knight for a pawn", "1k1r3q/1ppn3p/p4b2/4p3/8/P2N2P1/1PP1R1BP/2K1Q3 w - - 0 1", "d3e5", -220},
+		{"equal trade", "4k3/8/3p4/4n3/8/5N2/8/4K3 w - - 0 1", "f3e5", 0},
+		{"quiet move to a defended square", "4k3/8/3p4/8/8/5N2/8/4K3 w - - 0 1", "f3e5", -320},
+		{"x-ray recapture", "3rk3/8/8/3p4/8/8/3R4/3RK3 w - - 0 1", "d2d5", 100},
+		{"king can't recapture a defended piece", "8/8/8/3pk3/8/3R4/8/3RK3 w - - 0 1", "d3d5", 100},
+		{"en passant", "4k3/8/8/3Pp3/8/8/8/4K3 w - e6 0 1", "d5e6", 100},
+		{"promotion", "4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a7a8q", 800},
+	}
+
+	for _, tt := range tests {
+		pos := mustParseFEN(t, tt.fen)
+		move, _ := ParseUCIMove(tt.move)
+		if got := pos.SEE(move); got != tt.expected {
+			t.Errorf("%s: expected SEE of %s to be %d, got %d", tt.name, tt.move, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package chess
+
+// SliderAttacks computes the squares attacked by a rook or bishop on square
+// given the occupancy of the board. There are several implementations with
+// different speed and memory trade-offs:
+//
+//   - MagicAttacks: fancy magic table lookup, the default
+//   - ClassicalAttacks: walks each ray square by square, the reference
+//     implementation the others are checked against
+//   - KoggeStoneAttacks: parallel prefix fills, which also work set-wise on
+//     many sliders at once
+//   - HyperbolaAttacks: hyperbola quintessence, using subtraction and bit
+//     reversal on per-square line masks
+//
+// RookAttacks and BishopAttacks use the magic backend unless the package is
+// built with one of the slider_classical, slider_koggestone or
+// slider_hyperbola build tags. Going through a concrete type chosen at
+// build time, rather than this interface, keeps the lookups inlinable.
+type SliderAttacks interface {
+	Rook(square int, occupancy Bitboard) Bitboard
+	Bishop(square int, occupancy Bitboard) Bitboard
+}
+
+// SliderBackends lists every implementation by name, for tests, benchmarks
+// and tools that want to pick one at runtime
+var SliderBackends = map[string]SliderAttacks{
+	"magic":      MagicAttacks{},
+	"classical":  ClassicalAttacks{},
+	"koggestone": KoggeStoneAttacks{},
+	"hyperbola":  HyperbolaAttacks{},
+}
+
+type MagicAttacks struct{}
+
+func (MagicAttacks) Rook(square int, occupancy Bitboard) Bitboard {
+	return magicAttackTable[RookMagicEntries[square].Index(occupancy)]
+}
+
+func (MagicAttacks) Bishop(square int, occupancy Bitboard) Bitboard {
+	return magicAttackTable[BishopMagicEntries[square].Index(occupancy)]
+}
+
+type ClassicalAttacks struct{}
+
+func (ClassicalAttacks) Rook(square int, occupancy Bitboard) Bitboard {
+	return ComputeRookAttacks(square, occupancy)
+}
+
+func (ClassicalAttacks) Bishop(square int, occupancy Bitboard) Bitboard {
+	return ComputeBishopAttacks(square, occupancy)
+}
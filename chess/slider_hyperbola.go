@@ -0,0 +1,79 @@
+package chess
+
+import "math/bits"
+
+// HyperbolaAttacks uses hyperbola quintessence: along a line through the
+// slider, o - 2s clears the squares up to and including the first blocker
+// above the slider, and doing the same on the reversed board finds the
+// first blocker below it.
+type HyperbolaAttacks struct{}
+
+// the rank, file, diagonal and anti-diagonal through each square, not
+// including the square itself
+var (
+	rankMasks         [64]Bitboard
+	fileMasks         [64]Bitboard
+	diagonalMasks     [64]Bitboard
+	antiDiagonalMasks [64]Bitboard
+)
+
+func init() {
+	for sq := 0; sq < 64; sq++ {
+		rank, file := sq/8, sq%8
+		for other := 0; other < 64; other++ {
+			if other == sq {
+				continue
+			}
+			otherRank, otherFile := other/8, other%8
+			bit := Bitboard(1) << other
+
+			if otherRank == rank {
+				rankMasks[sq] |= bit
+			}
+			if otherFile == file {
+				fileMasks[sq] |= bit
+			}
+			if otherFile-otherRank == file-rank {
+				diagonalMasks[sq] |= bit
+			}
+			if otherFile+otherRank == file+rank {
+				antiDiagonalMasks[sq] |= bit
+			}
+		}
+	}
+}
+
+// lineAttacks finds the attacks along a file or diagonal. Mirroring the
+// board vertically with a byte swap keeps those lines intact, so it serves
+// as the reversal.
+func lineAttacks(square int, occupancy, mask Bitboard) Bitboard {
+	slider := uint64(1) << square
+	o := uint64(occupancy & mask)
+
+	forward := o - 2*slider
+	backward := bits.ReverseBytes64(bits.ReverseBytes64(o) - 2*bits.ReverseBytes64(slider))
+
+	return Bitboard(forward^backward) & mask
+}
+
+// rankAttacks is lineAttacks for ranks, which a byte swap leaves in place,
+// so it needs the full bit reversal instead
+func rankAttacks(square int, occupancy Bitboard) Bitboard {
+	slider := uint64(1) << square
+	mask := rankMasks[square]
+	o := uint64(occupancy & mask)
+
+	forward := o - 2*slider
+	backward := bits.Reverse64(bits.Reverse64(o) - 2*bits.Reverse64(slider))
+
+	return Bitboard(forward^backward) & mask
+}
+
+func (HyperbolaAttacks) Rook(square int, occupancy Bitboard) Bitboard {
+	return lineAttacks(square, occupancy, fileMasks[square]) | rankAttacks(square, occupancy)
+}
+
+func (HyperbolaAttacks) Bishop(square int, occupancy Bitboard) Bitboard {
+	return lineAttacks(square, occupancy, diagonalMasks[square]) |
+		lineAttacks(square, occupancy, antiDiagonalMasks[square])
+}
@@ -0,0 +1,5 @@
+//go:build slider_classical
+
+package chess
+
+type activeSliderAttacks = ClassicalAttacks
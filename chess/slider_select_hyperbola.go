@@ -0,0 +1,5 @@
+//go:build slider_hyperbola
+
+package chess
+
+type activeSliderAttacks = HyperbolaAttacks
@@ -0,0 +1,5 @@
+//go:build slider_koggestone
+
+package chess
+
+type activeSliderAttacks = KoggeStoneAttacks
@@ -0,0 +1,5 @@
+//go:build !slider_classical && !slider_koggestone && !slider_hyperbola
+
+package chess
+
+type activeSliderAttacks = MagicAttacks
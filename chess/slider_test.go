@@ -0,0 +1,79 @@
+package chess
+
+import (
+	"math/bits"
+	"sort"
+	"testing"
+)
+
+func sliderBackendNames() []string {
+	names := make([]string, 0, len(SliderBackends))
+	for name := range SliderBackends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// every backend must agree with the ray walk, including on the empty and
+// full boards and with the slider's own square occupied
+func TestSliderBackendsAgree(t *testing.T) {
+	occupancies := append(randomOccupancies(2000), 0, ^Bitboard(0))
+
+	for _, name := range sliderBackendNames() {
+		backend := SliderBackends[name]
+		for sq := 0; sq < 64; sq++ {
+			for _, occupancy := range occupancies {
+				occupancy |= Bitboard(1) << sq
+
+				if got, want := backend.Rook(sq, occupancy), ComputeRookAttacks(sq, occupancy); got != want {
+					t.Fatalf("%s: rook on %s with occupancy %#x: expected %#x, got %#x",
+						name, BitIndexToRankFile(sq), uint64(occupancy), uint64(want), uint64(got))
+				}
+				if got, want := backend.Bishop(sq, occupancy), ComputeBishopAttacks(sq, occupancy); got != want {
+					t.Fatalf("%s: bishop on %s with occupancy %#x: expected %#x, got %#x",
+						name, BitIndexToRankFile(sq), uint64(occupancy), uint64(want), uint64(got))
+				}
+			}
+		}
+	}
+}
+
+func TestKoggeStoneSetwiseAttacks(t *testing.T) {
+	occupancies := randomOccupancies(400)
+	for i := 0; i < len(occupancies); i += 2 {
+		// a handful of sliders scattered over the board
+		sliders := occupancies[i] & occupancies[i+1] & 0x5a3c99e7a5c3
+		occupancy := occupancies[i] | sliders
+
+		var rooks, bishops Bitboard
+		for bb := sliders; bb != 0; bb &= bb - 1 {
+			sq := bits.TrailingZeros64(uint64(bb))
+			rooks |= ComputeRookAttacks(sq, occupancy)
+			bishops |= ComputeBishopAttacks(sq, occupancy)
+		}
+
+		if got := KoggeStoneRookAttacks(sliders, occupancy); got != rooks {
+			t.Fatalf("rooks %#x with occupancy %#x: expected %#x, got %#x",
+				uint64(sliders), uint64(occupancy), uint64(rooks), uint64(got))
+		}
+		if got := KoggeStoneBishopAttacks(sliders, occupancy); got != bishops {
+			t.Fatalf("bishops %#x with occupancy %#x: expected %#x, got %#x",
+				uint64(sliders), uint64(occupancy), uint64(bishops), uint64(got))
+		}
+	}
+}
+
+func BenchmarkSliderBackends(b *testing.B) {
+	occupancies := randomOccupancies(1024)
+
+	for _, name := range sliderBackendNames() {
+		backend := SliderBackends[name]
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				occupancy := occupancies[i&1023]
+				benchmarkSink ^= backend.Rook(i&63, occupancy) | backend.Bishop(i&63, occupancy)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package chess
+
+// Clone returns a deep copy of the position, including its move history,
+// that can be changed without affecting the original. Copying a Position
+// by assignment isn't enough since both copies would share the backing
+// array of the history.
+func (p *Position) Clone() *Position {
+	clone := *p
+	clone.history = make([]Undo, len(p.history), cap(p.history))
+	copy(clone.history, p.history)
+	return &clone
+}
+
+// Snapshot holds the board state of a Position: bitboards, PieceMap,
+// castling rights, counters, hash and the cached check and pin state, but
+// not the move history. It has value semantics, so assigning a Snapshot or
+// passing it to another goroutine copies it, which makes it suitable for
+// copy-make search and for branching a position in tools.
+type Snapshot struct {
+	position Position // history is always nil
+}
+
+func (p *Position) Snapshot() Snapshot {
+	s := Snapshot{position: *p}
+	s.position.history = nil
+	return s
+}
+
+// Position returns a new Position set up from the snapshot, with an empty
+// move history
+func (s Snapshot) Position() *Position {
+	pos := s.position
+	return &pos
+}
+
+// MakeMove returns the snapshot reached by playing move, leaving s as it
+// is (copy-make)
+func (s Snapshot) MakeMove(move Move) Snapshot {
+	s.position.makeMove(move)
+	return s
+}
+
+func (s *Snapshot) GenerateLegalMoves() []Move {
+	return s.position.GenerateLegalMoves()
+}
+
+func (s *Snapshot) Hash() uint64 {
+	return s.position.Hash
+}
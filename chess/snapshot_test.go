@@ -0,0 +1,126 @@
+package chess
+
+import "testing"
+
+func TestCloneDoesNotAliasHistory(t *testing.T) {
+	pos := NewPosition()
+	pos.ApplyMove("e2e4")
+	pos.ApplyMove("e7e5")
+	pos.UnmakeMove() // leaves spare capacity in the history
+
+	clone := pos.Clone()
+	if !samePosition(*clone, *pos) || len(clone.history) != len(pos.history) {
+		t.Fatalf("expected the clone to match the original")
+	}
+
+	// both append to their history, which would overwrite each other's
+	// undo records if the backing array were shared
+	pos.ApplyMove("c7c5")
+	clone.ApplyMove("d7d5")
+
+	pos.UnmakeMove()
+	clone.UnmakeMove()
+	pos.UnmakeMove()
+	clone.UnmakeMove()
+
+	start := NewPosition()
+	if !samePosition(*pos, *start) {
+		t.Errorf("expected the original to unwind back to the start position")
+	}
+	if !samePosition(*clone, *start) {
+		t.Errorf("expected the clone to unwind back to the start position")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	pos := mustParseFEN(t, kiwipeteFEN)
+	pos.ApplyMove("e1g1")
+
+	snapshot := pos.Snapshot()
+	copied := snapshot
+
+	next := snapshot.MakeMove(move("e8c8"))
+	if !samePosition(snapshot.position, copied.position) {
+		t.Errorf("expected MakeMove to leave the snapshot unchanged")
+	}
+	if next.Hash() == snapshot.Hash() {
+		t.Errorf("expected the hash to change after a move")
+	}
+
+	fromSnapshot := snapshot.Position()
+	if !samePosition(*fromSnapshot, *pos) || len(fromSnapshot.history) != 0 {
+		t.Errorf("expected the position to match the snapshot without history")
+	}
+
+	pos.ApplyMove("e8c8")
+	if !samePosition(*next.Position(), *pos) {
+		t.Errorf("expected copy-make and MakeMove to reach the same position")
+	}
+}
+
+func perftCopyMake(s Snapshot, depth int) uint64 {
+	moves := s.GenerateLegalMoves()
+	if depth <= 1 {
+		return uint64(len(moves))
+	}
+
+	nodes := uint64(0)
+	for _, m := range moves {
+		nodes += perftCopyMake(s.MakeMove(m), depth-1)
+	}
+	return nodes
+}
+
+func TestPerftCopyMake(t *testing.T) {
+	pos := mustParseFEN(t, kiwipeteFEN)
+	if got := perftCopyMake(pos.Snapshot(), 3); got != 97862 {
+		t.Errorf("expected 97862 nodes, got %d", got)
+	}
+}
+
+// The make/unmake and copy-make benchmarks walk the same tree so their
+// timings can be compared directly
+func BenchmarkPerftMakeUnmake(b *testing.B) {
+	pos := mustParseFEN(b, kiwipeteFEN)
+	for i := 0; i < b.N; i++ {
+		pos.Perft(3)
+	}
+}
+
+func BenchmarkPerftCopyMake(b *testing.B) {
+	snapshot := mustParseFEN(b, kiwipeteFEN).Snapshot()
+	for i := 0; i < b.N; i++ {
+		perftCopyMake(snapshot, 3)
+	}
+}
+
+func BenchmarkMakeUnmake(b *testing.B) {
+	pos := mustParseFEN(b, kiwipeteFEN)
+	moves := pos.GenerateLegalMoves()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m := moves[i%len(moves)]
+		pos.MakeMove(m)
+		pos.UnmakeMove()
+	}
+}
+
+func BenchmarkCopyMake(b *testing.B) {
+	snapshot := mustParseFEN(b, kiwipeteFEN).Snapshot()
+	moves := snapshot.GenerateLegalMoves()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = snapshot.MakeMove(moves[i%len(moves)])
+	}
+}
+
+func BenchmarkClone(b *testing.B) {
+	pos := NewPosition()
+	for _, m := range []string{"e2e4", "e7e5", "g1f3", "b8c6", "f1b5", "a7a6"} {
+		pos.ApplyMove(m)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = pos.Clone()
+	}
+}
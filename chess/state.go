@@ -0,0 +1,92 @@
+package chess
+
+// StateInfo caches information about the current position that is needed
+// by legal move filtering, check detection and evaluation, so it doesn't
+// have to be recomputed every time it is asked for. It is refreshed after
+// every move by UpdateState.
+type StateInfo struct {
+	// enemy pieces giving check to the king of the side to move
+	Checkers Bitboard
+
+	// Blockers[c] holds every piece, of either color, that is the only
+	// piece standing between c's king and an enemy slider. Pinned[c] is
+	// the subset of those belonging to c.
+	Blockers [2]Bitboard
+	Pinned   [2]Bitboard
+
+	// CheckSquares[pieceType] holds the squares from which a piece of that
+	// type, belonging to the side to move, would attack the enemy king
+	CheckSquares [6]Bitboard
+}
+
+// UpdateState recomputes p.State from the bitboards. ApplyMove calls it
+// after every move; positions built by hand with SetPiece should call it
+// once they are fully set up.
+func (p *Position) UpdateState() {
+	us := p.ColorToMove()
+	them := us ^ 1
+	occupancy := p.GetOccupiedSquares()
+
+	p.State.Checkers = 0
+	if ksq := p.KingSquare(us); ksq != NoSquare {
+		p.State.Checkers = p.AttackersTo(ksq, occupancy) & p.PiecesOfColor(them)
+	}
+
+	for color := White; color <= Black; color++ {
+		p.State.Blockers[color] = p.sliderBlockers(color, occupancy)
+		p.State.Pinned[color] = p.State.Blockers[color] & p.PiecesOfColor(color)
+	}
+
+	p.State.CheckSquares = [6]Bitboard{}
+	if ksq := p.KingSquare(them); ksq != NoSquare {
+		p.State.CheckSquares[Pawn] = PawnAttacks[them][ksq]
+		p.State.CheckSquares[Knight] = KnightAttacks[ksq]
+		p.State.CheckSquares[Bishop] = BishopAttacks(ksq, occupancy)
+		p.State.CheckSquares[Rook] = RookAttacks(ksq, occupancy)
+		p.State.CheckSquares[Queen] = p.State.CheckSquares[Bishop] | p.State.CheckSquares[Rook]
+	}
+}
+
+// sliderBlockers finds the pieces that are the sole obstacle between the
+// king of the given color and an enemy rook, bishop or queen.
+func (p *Position) sliderBlockers(color int, occupancy Bitboard) Bitboard {
+	ksq := p.KingSquare(color)
+	if ksq == NoSquare {
+		return 0
+	}
+
+	enemy := color ^ 1
+	queens := p.PiecesOfType(enemy, Queen)
+	snipers := RookAttacks(ksq, 0)&(p.PiecesOfType(enemy, Rook)|queens) |
+		BishopAttacks(ksq, 0)&(p.PiecesOfType(enemy, Bishop)|queens)
+
+	blockers := Bitboard(0)
+	for snipers != 0 {
+		sniper := PopLSB(&snipers)
+		between := Between[ksq][sniper] & occupancy
+		if between != 0 && between&(between-1) == 0 {
+			blockers |= between
+		}
+	}
+
+	return blockers
+}
+
+// AttackersTo returns every piece, of either color, that attacks square
+// given the occupancy of the board.
+func (p *Position) AttackersTo(square int, occupancy Bitboard) Bitboard {
+	rooksQueens := p.WhiteRooks | p.BlackRooks | p.WhiteQueens | p.BlackQueens
+	bishopsQueens := p.WhiteBishops | p.BlackBishops | p.WhiteQueens | p.BlackQueens
+
+	return PawnAttacks[Black][square]&p.WhitePawns |
+		PawnAttacks[White][square]&p.BlackPawns |
+		KnightAttacks[square]&(p.WhiteKnights|p.BlackKnights) |
+		KingAttacks[square]&(p.WhiteKing|p.BlackKing) |
+		RookAttacks(square, occupancy)&rooksQueens |
+		BishopAttacks(square, occupancy)&bishopsQueens
+}
+
+// InCheck reports whether the side to move is in check
+func (p *Position) InCheck() bool {
+	return p.State.Checkers != 0
+}
@@ -0,0 +1,134 @@
+package chess
+
+import "testing"
+
+func TestCheckers(t *testing.T) {
+	pos := Position{}
+	pos.SetPiece('K', "e1")
+	pos.SetPiece('k', "e8")
+	pos.SetPiece('r', "e5")
+	pos.SetPiece('n', "f3")
+	pos.SetPiece('b', "b4")
+	pos.SetPiece('P', "d2") // blocks the bishop
+	pos.SideToMove = "white"
+	pos.UpdateState()
+
+	expected := squares("e5", "f3")
+	if pos.State.Checkers != expected {
+		t.Errorf("expected checkers: \n%sgot: \n%s", ToString(expected), ToString(pos.State.Checkers))
+	}
+	if !pos.InCheck() {
+		t.Errorf("expected white to be in check")
+	}
+
+	// black isn't in check, even though the white pawn could be captured
+	pos.SideToMove = "black"
+	pos.UpdateState()
+	if pos.InCheck() {
+		t.Errorf("expected black not to be in check, checkers: \n%s", ToString(pos.State.Checkers))
+	}
+}
+
+func TestPawnCheckers(t *testing.T) {
+	pos := Position{}
+	pos.SetPiece('K', "d4")
+	pos.SetPiece('p', "e5")
+	pos.SetPiece('p', "d5") // directly in front, not a check
+	pos.SetPiece('p', "c3") // black pawns don't capture backwards
+	pos.SideToMove = "white"
+	pos.UpdateState()
+
+	if pos.State.Checkers != squares("e5") {
+		t.Errorf("expected only the pawn on e5 to give check, got: \n%s", ToString(pos.State.Checkers))
+	}
+}
+
+func TestPinned(t *testing.T) {
+	pos := Position{}
+	pos.SetPiece('K', "e1")
+	pos.SetPiece('N', "e2") // pinned by the rook on e8
+	pos.SetPiece('r', "e8")
+	pos.SetPiece('B', "c3") // pinned by the bishop on a5
+	pos.SetPiece('b', "a5")
+	pos.SetPiece('R', "g1") // two pieces between, not pinned
+	pos.SetPiece('P', "f1")
+	pos.SetPiece('q', "h1")
+	pos.SetPiece('p', "g3") // black piece blocking the queen on h4
+	pos.SetPiece('q', "h4")
+
+	pos.SetPiece('k', "a8")
+	pos.SetPiece('n', "b7") // pinned by white queen on h1
+	pos.SideToMove = "white"
+	pos.UpdateState()
+
+	expected := squares("e2", "c3")
+	if pos.State.Pinned[White] != expected {
+		t.Errorf("expected white pinned: \n%sgot: \n%s", ToString(expected), ToString(pos.State.Pinned[White]))
+	}
+
+	expected = squares("e2", "c3", "g3")
+	if pos.State.Blockers[White] != expected {
+		t.Errorf("expected white blockers: \n%sgot: \n%s", ToString(expected), ToString(pos.State.Blockers[White]))
+	}
+
+	if pos.State.Pinned[Black] != 0 {
+		t.Errorf("expected no black pinned pieces, got: \n%s", ToString(pos.State.Pinned[Black]))
+	}
+
+	pos.RemovePiece("h1")
+	pos.SetPiece('Q', "h1")
+	pos.UpdateState()
+	if pos.State.Pinned[Black] != squares("b7") {
+		t.Errorf("expected knight on b7 to be pinned, got: \n%s", ToString(pos.State.Pinned[Black]))
+	}
+}
+
+func TestCheckSquares(t *testing.T) {
+	pos := Position{}
+	pos.SetPiece('K', "a1")
+	pos.SetPiece('k', "e5")
+	pos.SetPiece('p', "e7")
+	pos.SideToMove = "white"
+	pos.UpdateState()
+
+	tests := []struct {
+		pieceType int
+		name      string
+		expected  Bitboard
+	}{
+		{Pawn, "pawn", squares("d4", "f4")},
+		{Knight, "knight", KnightAttacks[square("e5")]},
+		{Bishop, "bishop", ComputeBishopAttacks(square("e5"), pos.GetOccupiedSquares())},
+		{Rook, "rook", squares("e6", "e7", "e4", "e3", "e2", "e1", "a5", "b5", "c5", "d5", "f5", "g5", "h5")},
+		{King, "king", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pos.State.CheckSquares[tt.pieceType]
+			if got != tt.expected {
+				t.Errorf("expected: \n%sgot: \n%s", ToString(tt.expected), ToString(got))
+			}
+		})
+	}
+
+	queen := pos.State.CheckSquares[Rook] | pos.State.CheckSquares[Bishop]
+	if pos.State.CheckSquares[Queen] != queen {
+		t.Errorf("expected queen check squares to be the union of rook and bishop check squares")
+	}
+}
+
+func TestStateUpdatedAfterMove(t *testing.T) {
+	pos := Position{}
+	pos.SetPiece('K', "e1")
+	pos.SetPiece('k', "a8")
+	pos.SetPiece('R', "h2")
+	pos.SideToMove = "white"
+	pos.UpdateState()
+
+	pos.ApplyMove("h2h8")
+
+	if pos.State.Checkers != squares("h8") {
+		t.Errorf("expected the rook on h8 to give check after the move, got: \n%s", ToString(pos.State.Checkers))
+	}
+}
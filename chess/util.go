@@ -62,6 +62,13 @@ func ToUpper(b byte) byte {
 	return b
 }
 
+func toLower(b byte) byte {
+	if b >= 'A' && b <= 'Z' {
+		return b - 'A' + 'a'
+	}
+	return b
+}
+
 func BitIndexToRankFile(index int) string {
 	file := index % 8
 	rank := index / 8
@@ -76,6 +83,10 @@ func ToUCINotation(move Move) string {
 	from := BitIndexToRankFile(move.From)
 	to := BitIndexToRankFile(move.To)
 
+	if move.Promo != 0 {
+		return fmt.Sprintf("%s%s%c", from, to, move.Promo)
+	}
+
 	return fmt.Sprintf("%s%s", from, to)
 }
 
@@ -90,6 +101,22 @@ func ParseMove(move string) (from, to int, promotionType byte) {
 	return
 }
 
+// ParseUCIMove converts a move in UCI notation, e.g. "e2e4" or "e7e8q", to a
+// Move. Unlike ParseMove it checks that the string is well formed.
+func ParseUCIMove(move string) (Move, error) {
+	if len(move) != 4 && len(move) != 5 {
+		return Move{}, fmt.Errorf("invalid move %q", move)
+	}
+	for i := 0; i < 4; i += 2 {
+		if move[i] < 'a' || move[i] > 'h' || move[i+1] < '1' || move[i+1] > '8' {
+			return Move{}, fmt.Errorf("invalid move %q", move)
+		}
+	}
+
+	from, to, promotion := ParseMove(move)
+	return Move{From: from, To: to, Promo: promotion}, nil
+}
+
 func PopLSB(bb *Bitboard) int {
 	lsb := *bb & -*bb                           // isolate least significant bit
 	square := bits.TrailingZeros64(uint64(lsb)) // returns 0-63
@@ -0,0 +1,87 @@
+package chess
+
+import (
+	"math/bits"
+	"math/rand"
+)
+
+// Zobrist keys used to hash positions. The generator is seeded with a fixed
+// value so hashes are the same from one run to the next.
+var (
+	zobristPieces     [12][64]uint64
+	zobristCastling   [16]uint64
+	zobristEnPassant  [8]uint64
+	zobristSideToMove uint64
+)
+
+func init() {
+	r := rand.New(rand.NewSource(0x5EED))
+
+	for piece := range zobristPieces {
+		for sq := range zobristPieces[piece] {
+			zobristPieces[piece][sq] = r.Uint64()
+		}
+	}
+	for i := range zobristCastling {
+		zobristCastling[i] = r.Uint64()
+	}
+	for file := range zobristEnPassant {
+		zobristEnPassant[file] = r.Uint64()
+	}
+	zobristSideToMove = r.Uint64()
+}
+
+// pieceIndex maps a piece character to 0-5 for white and 6-11 for black
+func pieceIndex(piece byte) int {
+	return PieceColor(piece)*6 + PieceType(piece)
+}
+
+func pieceKey(piece byte, square int) uint64 {
+	return zobristPieces[pieceIndex(piece)][square]
+}
+
+func (p *Position) castlingKey() uint64 {
+	index := 0
+	if p.WhiteCastlingRights.Short {
+		index |= 1
+	}
+	if p.WhiteCastlingRights.Long {
+		index |= 2
+	}
+	if p.BlackCastlingRights.Short {
+		index |= 4
+	}
+	if p.BlackCastlingRights.Long {
+		index |= 8
+	}
+	return zobristCastling[index]
+}
+
+func (p *Position) enPassantKey() uint64 {
+	if p.EnPassantTarget == 0 {
+		return 0
+	}
+	return zobristEnPassant[bits.TrailingZeros64(uint64(p.EnPassantTarget))%8]
+}
+
+// ComputeHash calculates the Zobrist hash of the position from scratch.
+// MakeMove keeps p.Hash up to date incrementally; positions built by hand
+// should set p.Hash = p.ComputeHash() once they are set up.
+func (p *Position) ComputeHash() uint64 {
+	hash := uint64(0)
+
+	for sq, piece := range p.PieceMap {
+		if piece != 0 {
+			hash ^= pieceKey(piece, sq)
+		}
+	}
+
+	hash ^= p.castlingKey()
+	hash ^= p.enPassantKey()
+
+	if p.ColorToMove() == Black {
+		hash ^= zobristSideToMove
+	}
+
+	return hash
+}
@@ -4,7 +4,9 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"runtime"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -12,103 +14,100 @@ import (
 	"github.com/liam-hatcher/gohobbyengine/uci"
 )
 
-func generateRookMagicFile() {
-	log.Info("Generating rook magic values")
-
-	total := 64
-	progressCount := 0
-	progress := func() {
-		progressCount++
-		percent := float64(progressCount) / float64(total) * 100
-		blocks := int(percent / 2) // 50 chars wide
-		fmt.Printf("\r[%s%s] %.1f%%", strings.Repeat("█", blocks), strings.Repeat(" ", 50-blocks), percent)
-	}
-
-	magics, rookAttackTables := chess.GenerateRookMagic(progress)
-	fmt.Println()
-
-	log.Info("Rook magic generation done!")
-
-	f, err := os.Create("chess/rook_magics.go")
+// writeMagicFile writes the magic numbers for one piece type, and the number
+// of index bits each was found for, to a Go file in the chess package. The
+// masks and attack tables are built from them when the chess package is
+// initialised.
+func writeMagicFile(path, piece, varPrefix string, result chess.MagicResult) {
+	f, err := os.Create(path)
 	if err != nil {
 		log.WithError(err).Error("Error creating file")
 		return
 	}
 	defer f.Close()
 
-	log.Info("Writing rook magic numbers to rook_magics.go")
+	log.Infof("Writing %s magic numbers to %s", piece, path)
 
 	// Write file header
 	fmt.Fprintln(f, "// Code generated by magic generator; DO NOT EDIT.")
-	fmt.Fprintln(f, "// This file contains precomputed rook magics and attack tables.")
+	fmt.Fprintf(f, "// This file contains precomputed %s magic numbers.\n", piece)
 	fmt.Fprintln(f)
 	fmt.Fprintln(f, "package chess")
 	fmt.Fprintln(f)
 
-	// Write rook magics
-	fmt.Fprintln(f, "var RookMagics = [64]uint64{")
-	for _, m := range magics {
-		fmt.Fprintf(f, "    0x%016X,\n", m)
+	fmt.Fprintf(f, "var %sMagics = [64]uint64{\n", varPrefix)
+	for _, m := range result.Magics {
+		fmt.Fprintf(f, "\t0x%016X,\n", m)
 	}
 	fmt.Fprintln(f, "}")
-	log.Info("Rook magic numbers written to rook_magics.go")
-
-	// Write rook attack tables
-	log.Info("Writing rook attack tables to rook_magics.go")
-	fmt.Fprintln(f, "// Precomputed rook attack tables")
-	fmt.Fprintln(f, "var RookAttackTables = [64][]Bitboard{")
-	for sq, table := range rookAttackTables {
-		fmt.Fprintf(f, "    /* %s */ {\n", chess.BitIndexToRankFile(sq))
-		for _, attacks := range table {
-			fmt.Fprintf(f, "        0x%016X,\n", attacks)
-		}
-		fmt.Fprintln(f, "    },")
+	fmt.Fprintln(f)
+
+	// the magics index tables of 1<<bits entries, which can be fewer than
+	// the relevant bits of the mask when searching for tighter magics
+	fmt.Fprintf(f, "var %sMagicBits = [64]int{\n", varPrefix)
+	for _, b := range result.Bits {
+		fmt.Fprintf(f, "\t%d,\n", b)
 	}
 	fmt.Fprintln(f, "}")
-	log.Info("Rook attack tables written to rook_magics.go")
-
-	log.Info("Generating relevantBits mapping")
-	// Compute relevant bits for each square from the mask
-	relevantBits := make([]int, 64)
-	for sq := 0; sq < 64; sq++ {
-		mask := chess.RookRelevantMask(sq)
-		relevantBits[sq] = chess.PopCount(mask) // popCount = number of 1s in mask
+	log.Infof("%s magic numbers written to %s", piece, path)
+}
+
+func progressBar() func() {
+	total := 64
+	progressCount := 0
+	return func() {
+		progressCount++
+		percent := float64(progressCount) / float64(total) * 100
+		blocks := int(percent / 2) // 50 chars wide
+		fmt.Printf("\r[%s%s] %.1f%%", strings.Repeat("█", blocks), strings.Repeat(" ", 50-blocks), percent)
 	}
+}
 
-	// Write rook relevant bits
-	fmt.Fprintln(f, "var RookRelevantBitsMap = [64]int{")
-	for _, rb := range relevantBits {
-		fmt.Fprintf(f, "    %d,\n", rb)
+func generateMagicFile(pieceType int, path string, opts chess.MagicSearchOptions) {
+	piece, varPrefix := "rook", "Rook"
+	if pieceType == chess.Bishop {
+		piece, varPrefix = "bishop", "Bishop"
 	}
-	fmt.Fprintln(f, "}")
-	log.Info("Rook relevant bits written to rook_magics.go")
 
-	log.Info("Success!")
+	log.Infof("Generating %s magic values (seed %d)", piece, opts.Seed)
 
-	log.Info("Generating rook masks for all 64 squares")
+	start := time.Now()
+	result := chess.GenerateMagics(pieceType, opts, progressBar())
+	fmt.Println()
 
-	rookMasks := [64]chess.Bitboard{}
-	for sq := 0; sq < 64; sq++ {
-		mask := chess.RookRelevantMask(sq)
-		rookMasks[sq] = mask
-		log.Debugf("Rook mask for %s = 0x%016X", chess.BitIndexToRankFile(sq), mask)
+	tableSize := 0
+	for _, b := range result.Bits {
+		tableSize += 1 << b
 	}
+	log.Infof("%s magic generation done in %s, %d table entries", piece, time.Since(start).Round(time.Millisecond), tableSize)
 
-	// Write to file
-	fmt.Fprintln(f, "var RookMasks = [64]Bitboard{")
-	for _, mask := range rookMasks {
-		fmt.Fprintf(f, "    0x%016X,\n", mask)
-	}
-	fmt.Fprintln(f, "}")
-	log.Info("Rook masks written to rook_magics.go")
+	writeMagicFile(path, piece, varPrefix, result)
 }
 
 func parseFlags() bool {
-	genRook := flag.Bool("gen-rook-magics", false, "Generate rook magic numbers and attack tables")
+	genRook := flag.Bool("gen-rook-magics", false, "Generate rook magic numbers")
+	genBishop := flag.Bool("gen-bishop-magics", false, "Generate bishop magic numbers")
+	rookOut := flag.String("rook-magics-out", "chess/rook_magics.go", "File to write generated rook magics to")
+	bishopOut := flag.String("bishop-magics-out", "chess/bishop_magics.go", "File to write generated bishop magics to")
+	seed := flag.Int64("seed", 0, "Seed for the magic number search, the same seed gives the same magics")
+	workers := flag.Int("magic-workers", runtime.NumCPU(), "Number of goroutines searching for magics")
+	reduceBits := flag.Int("magic-reduce-bits", 0, "Also search for magics using up to this many fewer index bits")
 	flag.Parse()
 
+	opts := chess.MagicSearchOptions{
+		Seed:       *seed,
+		Workers:    *workers,
+		ReduceBits: *reduceBits,
+	}
+
 	if *genRook {
-		generateRookMagicFile()
+		generateMagicFile(chess.Rook, *rookOut, opts)
+	}
+	if *genBishop {
+		generateMagicFile(chess.Bishop, *bishopOut, opts)
+	}
+	if *genRook || *genBishop {
+		log.Info("Success!")
 		return true
 	}
 
@@ -0,0 +1,52 @@
+package search
+
+import (
+	"time"
+
+	"github.com/liam-hatcher/gohobbyengine/chess"
+)
+
+// BenchPositions is a fixed set of positions searched by Bench, covering
+// the opening, tactical middlegames and endgames
+var BenchPositions = []string{
+	chess.StartFEN,
+	"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+	"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+	"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+	"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+	"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+	"r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4",
+	"2r3k1/pp3ppp/2n5/3p4/3P4/2N5/PP3PPP/2R3K1 w - - 0 1",
+	"8/8/4k3/3p4/3P4/4K3/8/8 w - - 0 1",
+	"6k1/5ppp/8/8/8/8/5PPP/3R2K1 w - - 0 1",
+}
+
+// BenchResult is the outcome of searching one of the BenchPositions
+type BenchResult struct {
+	FEN    string
+	Result Result
+}
+
+// Bench searches each of the BenchPositions to depth with a new Searcher
+// and returns the total number of nodes searched and the time taken. The
+// node count only changes when the search itself does, which makes it a
+// quick check of whether a change, e.g. to move ordering, shrank the tree.
+// report, if not nil, is called after each position.
+func Bench(depth int, report func(BenchResult)) (nodes uint64, elapsed time.Duration) {
+	start := time.Now()
+
+	for _, fen := range BenchPositions {
+		pos, err := chess.NewPositionFromFEN(fen)
+		if err != nil {
+			panic(err)
+		}
+
+		result := NewSearcher().Search(pos, Limits{Depth: depth})
+		nodes += result.Nodes
+		if report != nil {
+			report(BenchResult{FEN: fen, Result: result})
+		}
+	}
+
+	return nodes, time.Since(start)
+}
@@ -0,0 +1,239 @@
+package search
+
+import (
+	"sort"
+
+	"github.com/liam-hatcher/gohobbyengine/chess"
+)
+
+// the stages of a movePicker, in the order moves are returned
+const (
+	stageTTMove = iota
+	stageGenerateCaptures
+	stageGoodCaptures
+	stageKillers
+	stageGenerateQuiets
+	stageQuiets
+	stageBadCaptures
+	stageDone
+)
+
+// scores for the capture stage: winning captures go before queen
+// promotions that don't capture, ordered by MVV-LVA among themselves
+const (
+	goodCaptureScore = 1 << 20
+	promotionScore   = 1 << 19
+)
+
+type scoredMove struct {
+	move  chess.Move
+	score int
+}
+
+// movePicker hands out the pseudo-legal moves of a position one at a time,
+// best first by a guess of their strength: the transposition table move,
+// captures that don't lose material by SEE, ordered by MVV-LVA, queen
+// promotions, the two killer moves, quiet moves by history and finally the
+// captures that lose material. Moves are generated a stage at a time, so
+// if an early move causes a cutoff the rest are never generated.
+type movePicker struct {
+	pos     *chess.Position
+	history *historyTable
+
+	ttMove  chess.Move
+	killers [2]chess.Move
+
+	stage       int
+	moves       []scoredMove
+	index       int
+	badCaptures []scoredMove
+}
+
+func newMovePicker(pos *chess.Position, ttMove chess.Move, killers [2]chess.Move, history *historyTable) *movePicker {
+	return &movePicker{
+		pos:     pos,
+		history: history,
+		ttMove:  ttMove,
+		killers: killers,
+		stage:   stageTTMove,
+	}
+}
+
+// next returns the next move, or false when there are none left. The moves
+// may leave the king in check, the caller checks legality.
+func (mp *movePicker) next() (chess.Move, bool) {
+	for {
+		switch mp.stage {
+		case stageTTMove:
+			mp.stage++
+			if mp.ttMove != (chess.Move{}) && mp.pos.IsPseudoLegal(mp.ttMove) {
+				return mp.ttMove, true
+			}
+
+		case stageGenerateCaptures:
+			mp.stage++
+			mp.scoreCaptures(mp.pos.GenerateCaptures())
+
+		case stageGoodCaptures:
+			if m, ok := mp.pick(); ok {
+				return m, true
+			}
+			mp.stage++
+			mp.index = 0
+
+		case stageKillers:
+			for mp.index < len(mp.killers) {
+				killer := mp.killers[mp.index]
+				mp.index++
+				if killer != mp.ttMove && mp.pos.IsPseudoLegal(killer) && mp.isQuiet(killer) {
+					return killer, true
+				}
+			}
+			mp.stage++
+
+		case stageGenerateQuiets:
+			mp.stage++
+			mp.scoreQuiets(mp.pos.GenerateQuiets())
+
+		case stageQuiets:
+			if m, ok := mp.pick(); ok {
+				return m, true
+			}
+			mp.stage++
+			mp.moves, mp.index = mp.badCaptures, 0
+
+		case stageBadCaptures:
+			if m, ok := mp.pick(); ok {
+				return m, true
+			}
+			mp.stage++
+
+		default:
+			return chess.Move{}, false
+		}
+	}
+}
+
+// pick returns the best scoring remaining move of the current stage by a
+// selection sort step, which is cheaper than sorting when a cutoff comes
+// early. Moves already returned in an earlier stage are skipped.
+func (mp *movePicker) pick() (chess.Move, bool) {
+	for mp.index < len(mp.moves) {
+		best := mp.index
+		for i := mp.index + 1; i < len(mp.moves); i++ {
+			if mp.moves[i].score > mp.moves[best].score {
+				best = i
+			}
+		}
+		mp.moves[mp.index], mp.moves[best] = mp.moves[best], mp.moves[mp.index]
+
+		m := mp.moves[mp.index].move
+		mp.index++
+		if m == mp.ttMove || mp.stage == stageQuiets && (m == mp.killers[0] || m == mp.killers[1]) {
+			continue
+		}
+		return m, true
+	}
+	return chess.Move{}, false
+}
+
+func (mp *movePicker) scoreCaptures(moves []chess.Move) {
+	mp.moves, mp.index = mp.moves[:0], 0
+
+	for _, m := range moves {
+		score := mvvLva(mp.pos, m)
+		switch {
+		case mp.pos.PieceMap[m.To] == 0 && !mp.isEnPassant(m):
+			score += promotionScore
+		case mp.pos.SEE(m) >= 0:
+			score += goodCaptureScore
+		default:
+			mp.badCaptures = append(mp.badCaptures, scoredMove{m, score})
+			continue
+		}
+		mp.moves = append(mp.moves, scoredMove{m, score})
+	}
+}
+
+func (mp *movePicker) scoreQuiets(moves []chess.Move) {
+	mp.moves, mp.index = mp.moves[:0], 0
+
+	color := mp.pos.ColorToMove()
+	for _, m := range moves {
+		mp.moves = append(mp.moves, scoredMove{m, mp.history.get(color, m)})
+	}
+}
+
+func (mp *movePicker) isEnPassant(m chess.Move) bool {
+	return chess.PieceType(mp.pos.PieceMap[m.From]) == chess.Pawn && chess.Bitboard(1)<<m.To == mp.pos.EnPassantTarget
+}
+
+// isQuiet reports whether m would be generated by GenerateQuiets
+func (mp *movePicker) isQuiet(m chess.Move) bool {
+	if m.Promo != 0 {
+		return m.Promo != 'q'
+	}
+	return mp.pos.PieceMap[m.To] == 0 && !mp.isEnPassant(m)
+}
+
+// mvvLva scores captures most valuable victim first, and among captures of
+// the same victim least valuable attacker first. Promotions count as
+// capturing the promoted-to piece.
+func mvvLva(pos *chess.Position, m chess.Move) int {
+	victim := 0
+	if captured := pos.PieceMap[m.To]; captured != 0 {
+		victim = pieceValues[chess.PieceType(captured)]
+	}
+	if m.Promo != 0 {
+		victim += pieceValues[chess.PieceType(m.Promo)]
+	}
+	return victim*8 - chess.PieceType(pos.PieceMap[m.From])
+}
+
+// orderCaptures sorts captures by MVV-LVA, for the quiescence search which
+// prunes the losing captures anyway
+func orderCaptures(pos *chess.Position, moves []chess.Move) {
+	sort.SliceStable(moves, func(i, j int) bool {
+		return mvvLva(pos, moves[i]) > mvvLva(pos, moves[j])
+	})
+}
+
+// historyMax bounds the history scores, and with the update rule in
+// historyTable.update keeps them from saturating
+const historyMax = 1 << 14
+
+// historyTable is the butterfly history: a score for every from/to square
+// pair of each color, raised for quiet moves that caused a beta cutoff and
+// lowered for the quiet moves searched before them without one
+type historyTable [2][64][64]int
+
+func (h *historyTable) get(color int, m chess.Move) int {
+	return h[color][m.From][m.To]
+}
+
+// update moves the score towards the bonus, the closer it already is the
+// slower, so frequently good moves settle near historyMax
+func (h *historyTable) update(color int, m chess.Move, bonus int) {
+	bonus = min(max(bonus, -historyMax), historyMax)
+	entry := &h[color][m.From][m.To]
+	*entry += bonus - *entry*abs(bonus)/historyMax
+}
+
+// age halves every score, so what was learnt in earlier searches still
+// counts but less than what is learnt in the next
+func (h *historyTable) age() {
+	for color := range h {
+		for from := range h[color] {
+			for to := range h[color][from] {
+				h[color][from][to] /= 2
+			}
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,88 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/liam-hatcher/gohobbyengine/chess"
+)
+
+func pickAll(mp *movePicker) []chess.Move {
+	var moves []chess.Move
+	for {
+		m, ok := mp.next()
+		if !ok {
+			return moves
+		}
+		moves = append(moves, m)
+	}
+}
+
+func mustParseMove(t *testing.T, uci string) chess.Move {
+	t.Helper()
+	m, err := chess.ParseUCIMove(uci)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestMovePickerReturnsEveryMoveOnce(t *testing.T) {
+	pos := mustParseFEN(t, "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	var history historyTable
+	ttMove := mustParseMove(t, "e2a6")
+	killers := [2]chess.Move{mustParseMove(t, "a2a3"), mustParseMove(t, "a1a8")}
+
+	picked := map[chess.Move]int{}
+	for _, m := range pickAll(newMovePicker(pos, ttMove, killers, &history)) {
+		picked[m]++
+	}
+
+	all := pos.GenerateMoves()
+	if len(picked) != len(all) {
+		t.Errorf("expected %d moves, got %d", len(all), len(picked))
+	}
+	for _, m := range all {
+		if picked[m] != 1 {
+			t.Errorf("expected %s to be picked once, got %d", chess.ToUCINotation(m), picked[m])
+		}
+	}
+}
+
+func TestMovePickerOrder(t *testing.T) {
+	// the knight can take the undefended rook on a8, while the queen can
+	// only take a pawn on f7 that the rook and king defend
+	pos := mustParseFEN(t, "r3k3/2p1rp2/1N1p4/8/8/8/5Q2/4K3 w - - 0 1")
+	var history historyTable
+	history.update(chess.White, mustParseMove(t, "f2h4"), 100)
+
+	killer := mustParseMove(t, "e1d1")
+	ttMove := mustParseMove(t, "f2f3")
+	moves := pickAll(newMovePicker(pos, ttMove, [2]chess.Move{killer, {}}, &history))
+
+	expected := []string{"f2f3", "b6a8", "e1d1", "f2h4"}
+	for i, uci := range expected {
+		if got := chess.ToUCINotation(moves[i]); got != uci {
+			t.Errorf("expected move %d to be %s, got %s", i, uci, got)
+		}
+	}
+	if got := chess.ToUCINotation(moves[len(moves)-1]); got != "f2f7" {
+		t.Errorf("expected the losing capture last, got %s", got)
+	}
+}
+
+func TestHistoryStaysBounded(t *testing.T) {
+	var history historyTable
+	m := mustParseMove(t, "e2e4")
+
+	for i := 0; i < 1000; i++ {
+		history.update(chess.White, m, 400)
+	}
+	if score := history.get(chess.White, m); score <= 0 || score > historyMax {
+		t.Errorf("expected the score to stay within %d, got %d", historyMax, score)
+	}
+
+	history.age()
+	if score := history.get(chess.White, m); score > historyMax/2 {
+		t.Errorf("expected ageing to halve the score, got %d", score)
+	}
+}
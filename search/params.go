@@ -0,0 +1,67 @@
+package search
+
+import "math"
+
+// Search parameters, kept together so they can be tuned in one place.
+const (
+	// null move pruning is tried from this depth, reducing the search of
+	// the null move by NullMoveReduction plies, one more for every
+	// NullMoveDepthDivisor plies of depth and for every NullMoveEvalDivisor
+	// centipawns the static evaluation is above beta, up to
+	// NullMoveMaxEvalReduction
+	NullMoveMinDepth         = 3
+	NullMoveReduction        = 3
+	NullMoveDepthDivisor     = 3
+	NullMoveEvalDivisor      = 200
+	NullMoveMaxEvalReduction = 3
+
+	// late move reductions apply from LMRMinDepth to the moves after the
+	// first LMRMinMoves, reducing by LMRBase + ln(depth)*ln(moves)/LMRDivisor
+	LMRMinDepth = 3
+	LMRMinMoves = 3
+	LMRBase     = 0.75
+	LMRDivisor  = 2.25
+
+	// from AspirationMinDepth the root is searched with a window of
+	// AspirationWindow centipawns either side of the previous score
+	AspirationMinDepth = 4
+	AspirationWindow   = 25
+
+	// margins for the forward pruning methods, in centipawns per ply of
+	// depth left, and the depths they apply to
+	ReverseFutilityMaxDepth = 6
+	ReverseFutilityMargin   = 90
+	FutilityMaxDepth        = 3
+	FutilityMargin          = 150
+	RazoringMaxDepth        = 2
+	RazoringMargin          = 300
+
+	// ProbCut looks for captures that beat beta by ProbCutMargin in a
+	// search ProbCutReduction plies shallower, from ProbCutMinDepth
+	ProbCutMinDepth  = 5
+	ProbCutMargin    = 200
+	ProbCutReduction = 4
+
+	// the TT move is tested for singularity from SingularMinDepth, if its
+	// entry is at most SingularDepthMargin plies shallower than the node,
+	// by searching the other moves to half depth against a beta
+	// SingularMargin centipawns per ply below its score
+	SingularMinDepth    = 6
+	SingularDepthMargin = 3
+	SingularMargin      = 2
+)
+
+// reductions holds the late move reduction for each depth and move number
+var reductions [MaxPly][64]int
+
+func init() {
+	for depth := 1; depth < MaxPly; depth++ {
+		for moves := 1; moves < 64; moves++ {
+			reductions[depth][moves] = int(LMRBase + math.Log(float64(depth))*math.Log(float64(moves))/LMRDivisor)
+		}
+	}
+}
+
+func lateMoveReduction(depth, moveNumber int) int {
+	return reductions[min(depth, MaxPly-1)][min(moveNumber, 63)]
+}
@@ -0,0 +1,106 @@
+package search
+
+import "github.com/liam-hatcher/gohobbyengine/chess"
+
+// deltaMargin is added to the value of a capture when deciding whether it
+// could possibly raise alpha, to allow for positional gains
+const deltaMargin = 200
+
+// quiescence extends the search past the horizon with captures and queen
+// promotions only, until the position is quiet, so that a leaf is never
+// scored in the middle of an exchange. The side to move may stand pat on
+// the static evaluation instead of capturing, unless it is in check, in
+// which case every evasion is searched. qply counts the plies since the
+// main search ended.
+func (s *Searcher) quiescence(ply, qply, alpha, beta int) int {
+	s.nodes++
+	s.selDepth = max(s.selDepth, ply)
+	if s.nodes%checkInterval == 0 || s.nodes == s.limits.Nodes {
+		s.checkLimits()
+	}
+	if s.stopped {
+		return 0
+	}
+
+	if s.pos.InCheck() {
+		return s.quiescenceEvasions(ply, qply, alpha, beta)
+	}
+
+	standPat := Evaluate(s.pos)
+	if standPat >= beta || ply >= MaxPly {
+		return standPat
+	}
+	if standPat+pieceValues[chess.Queen]+deltaMargin < alpha {
+		// not even winning a queen would be enough
+		return alpha
+	}
+	if standPat > alpha {
+		alpha = standPat
+	}
+
+	moves := s.pos.GenerateCaptures()
+	orderCaptures(s.pos, moves)
+	if s.Options.QuietChecks && qply == 0 {
+		for _, m := range s.pos.GenerateQuiets() {
+			if m.Promo == 0 && s.pos.GivesCheck(m) {
+				moves = append(moves, m)
+			}
+		}
+	}
+
+	for _, m := range moves {
+		if !s.pos.IsLegal(m) {
+			continue
+		}
+
+		if captured := s.pos.PieceMap[m.To]; captured != 0 && m.Promo == 0 {
+			// delta pruning, leaving alone captures that give check
+			if standPat+pieceValues[chess.PieceType(captured)]+deltaMargin <= alpha && !s.pos.GivesCheck(m) {
+				continue
+			}
+		}
+		if s.pos.SEE(m) < 0 {
+			continue
+		}
+
+		s.pos.MakeMove(m)
+		score := -s.quiescence(ply+1, qply+1, -beta, -alpha)
+		s.pos.UnmakeMove()
+
+		if score > alpha {
+			alpha = score
+			if alpha >= beta {
+				break
+			}
+		}
+	}
+
+	return alpha
+}
+
+// quiescenceEvasions searches every legal move when the side to move is in
+// check, since standing pat isn't an option then
+func (s *Searcher) quiescenceEvasions(ply, qply, alpha, beta int) int {
+	moves := s.pos.GenerateLegalMoves()
+	if len(moves) == 0 {
+		return -Mate + ply
+	}
+	if ply >= MaxPly {
+		return Evaluate(s.pos)
+	}
+
+	for _, m := range moves {
+		s.pos.MakeMove(m)
+		score := -s.quiescence(ply+1, qply+1, -beta, -alpha)
+		s.pos.UnmakeMove()
+
+		if score > alpha {
+			alpha = score
+			if alpha >= beta {
+				break
+			}
+		}
+	}
+
+	return alpha
+}
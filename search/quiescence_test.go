@@ -0,0 +1,49 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/liam-hatcher/gohobbyengine/chess"
+)
+
+func quiescenceScore(t *testing.T, fen string, options Options) int {
+	t.Helper()
+	s := NewSearcher()
+	s.Options = options
+	s.pos = mustParseFEN(t, fen)
+	return s.quiescence(0, 0, -Infinity, Infinity)
+}
+
+func TestQuiescenceResolvesExchanges(t *testing.T) {
+	tests := []struct {
+		name string
+		fen  string
+		// the expected material swing relative to the static evaluation
+		swing int
+	}{
+		{"hanging rook", "4k3/8/8/3r4/8/8/8/3QK3 w - - 0 1", 500},
+		{"defended pawn", "4k3/3r4/8/3p4/8/8/8/3QK3 w - - 0 1", 0},
+		{"quiet position", chess.StartFEN, 0},
+	}
+
+	for _, tt := range tests {
+		static := Evaluate(mustParseFEN(t, tt.fen))
+		score := quiescenceScore(t, tt.fen, DefaultOptions)
+
+		// piece placement shifts the score a little, the material shouldn't
+		if diff := score - static - tt.swing; diff < -100 || diff > 100 {
+			t.Errorf("%s: expected a score near %d, got %d", tt.name, static+tt.swing, score)
+		}
+	}
+}
+
+func TestQuiescenceQuietChecks(t *testing.T) {
+	fen := "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1"
+
+	if score := quiescenceScore(t, fen, Options{QuietChecks: true}); score != Mate-1 {
+		t.Errorf("expected quiet checks to find the back rank mate, got %d", score)
+	}
+	if score := quiescenceScore(t, fen, Options{}); score >= Mate-MaxPly {
+		t.Errorf("expected captures alone not to find the mate, got %d", score)
+	}
+}
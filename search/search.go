@@ -0,0 +1,792 @@
+// Package search finds the best move in a position with an alpha-beta
+// search over the legal moves generated by the chess package.
+package search
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/liam-hatcher/gohobbyengine/chess"
+)
+
+const (
+	// Infinity is larger than any score the search can return
+	Infinity = 32001
+
+	// Mate is the score for delivering checkmate at the root. A mate n
+	// plies away scores Mate-n, so shorter mates are preferred.
+	Mate = 32000
+
+	// MaxPly bounds how deep the search can go below the root
+	MaxPly = 128
+)
+
+// MateIn returns the number of moves to the mate a score announces, for
+// UCI's "score mate", and false for scores that aren't mates. It is
+// negative when the side to move is getting mated, or zero if it already
+// is.
+func MateIn(score int) (int, bool) {
+	switch {
+	case score >= Mate-MaxPly:
+		return (Mate - score + 1) / 2, true
+	case score <= -Mate+MaxPly:
+		return -(Mate + score) / 2, true
+	}
+	return 0, false
+}
+
+// Limits bounds a search. Zero values mean no limit, and a search without
+// any limits runs until the maximum depth.
+type Limits struct {
+	// Depth is the last iteration searched, in plies
+	Depth int
+
+	// Nodes stops the search after roughly this many nodes
+	Nodes uint64
+
+	// Mate stops the search once a mate in this many moves, or fewer, is
+	// found
+	Mate int
+
+	// MoveTime is the exact time to search for
+	MoveTime time.Duration
+
+	// the clock: the time left and the increment per move for each side,
+	// and the number of moves until the next time control, which is zero
+	// if the rest of the game has to be played in the time left
+	WhiteTime, BlackTime           time.Duration
+	WhiteIncrement, BlackIncrement time.Duration
+	MovesToGo                      int
+
+	// MoveOverhead is time kept back for each move to allow for
+	// communication delays, so the engine doesn't lose on time
+	MoveOverhead time.Duration
+
+	// PonderHit, if not nil, makes this a search on the opponent's time:
+	// the time limits are ignored until the channel is closed, and then
+	// apply as if the search had started when the opponent did. The time
+	// spent pondering is saved on the engine's own clock.
+	PonderHit <-chan struct{}
+
+	// SearchMoves, if not empty, restricts the search to these root moves
+	SearchMoves []chess.Move
+
+	// MultiPV is the number of best lines to search, each with a
+	// different first move. Zero means one.
+	MultiPV int
+}
+
+// Result is the outcome of the last completed iteration of a search
+type Result struct {
+	Move  chess.Move
+	Score int
+	Depth int
+	PV    []chess.Move
+
+	// SelDepth is the greatest number of plies from the root the search
+	// reached, counting extensions and the quiescence search
+	SelDepth int
+
+	// Nodes counts the nodes searched by all threads, in Time
+	Nodes uint64
+	Time  time.Duration
+
+	// MultiPV ranks the line among those searched, starting at 1 for the
+	// best one
+	MultiPV int
+}
+
+// Options switches optional parts of the search on and off
+type Options struct {
+	// QuietChecks also searches quiet moves that give check at the first
+	// ply of the quiescence search, which finds some mates and forks the
+	// captures alone miss at the cost of a larger tree
+	QuietChecks bool
+
+	// The forward pruning methods based on the static evaluation, see
+	// negamax. Each can be turned off, e.g. to measure what it's worth in
+	// self-play.
+	ReverseFutility bool
+	Futility        bool
+	Razoring        bool
+	ProbCut         bool
+}
+
+// DefaultOptions are the options used by NewSearcher
+var DefaultOptions = Options{
+	QuietChecks:     false,
+	ReverseFutility: true,
+	Futility:        true,
+	Razoring:        true,
+	ProbCut:         true,
+}
+
+// Searcher holds the state of a search. A Searcher can be reused for
+// several searches but not for concurrent ones.
+type Searcher struct {
+	Options Options
+
+	// Threads is the number of goroutines searching, see smp.go. Zero
+	// means one.
+	Threads int
+
+	// Skill, if not nil, weakens the search, see Skill
+	Skill *Skill
+
+	// TT is kept between searches, clear it when starting a new game
+	TT *TranspositionTable
+
+	// Info, if not nil, is called with the result of each iteration as it
+	// completes, once for each line with MultiPV
+	Info func(Result)
+
+	// CurrMove, if not nil, is called as the search of each root move
+	// starts, numbering the moves from 1, but only once the search has
+	// been running for currMoveDelay so that short searches aren't
+	// flooded with reports
+	CurrMove func(depth int, move chess.Move, number int)
+
+	pos       *chess.Position
+	nodes     uint64
+	rootDepth int
+	selDepth  int
+
+	// helpers are the searchers of the other threads, and main is the
+	// searcher that started a helper. thread numbers the helpers from 1,
+	// the main searcher is 0. published is the node count of a thread as
+	// last seen by the others.
+	helpers   []*Searcher
+	main      *Searcher
+	thread    int
+	published atomic.Uint64
+
+	ctx     context.Context
+	limits  Limits
+	tm      *timeManager
+	stopped bool
+
+	// the legal root moves that are searched, and with MultiPV, the index
+	// of the line being searched. The first moves of the lines before it
+	// are left out, so each line finds the best of the remaining moves.
+	rootMoves []chess.Move
+	pvIndex   int
+	lineMoves []chess.Move
+
+	// lines holds every line of the last completed iteration, best first.
+	// Only the first infoLines are reported, since a weakened search
+	// considers more lines than were asked for.
+	lines     []Result
+	infoLines int
+
+	killers [MaxPly][2]chess.Move
+	history historyTable
+	stack   [MaxPly]stackEntry
+
+	// the triangular PV table: pvTable[ply] holds the best line found from
+	// ply on, pvLength[ply] moves long
+	pvTable  [MaxPly][MaxPly]chess.Move
+	pvLength [MaxPly]int
+}
+
+// stackEntry holds what the search knows about each ply of the current line
+type stackEntry struct {
+	// null is set while a null move is being searched from this ply
+	null bool
+
+	// excluded is set while searching this ply without the TT move, to
+	// find out if it is singular
+	excluded chess.Move
+
+	// extensions counts the plies the line was extended by to reach this
+	// ply, which is limited to the depth of the iteration
+	extensions int
+
+	// captureTo is the square of the capture being searched from this ply,
+	// or chess.NoSquare for other moves
+	captureTo int
+}
+
+func NewSearcher() *Searcher {
+	return &Searcher{
+		Options: DefaultOptions,
+		TT:      NewTranspositionTable(DefaultHashMB),
+	}
+}
+
+// Search runs an iterative deepening search of pos within limits and
+// returns the best move found. pos itself is left untouched, the search
+// works on a copy. If the side to move has no legal moves the result has
+// an empty PV and the score of the final position.
+func (s *Searcher) Search(pos *chess.Position, limits Limits) Result {
+	return s.SearchContext(context.Background(), pos, limits)
+}
+
+// SearchContext is Search, stopping early when ctx is cancelled. The
+// result is then that of the last completed iteration, or if the first
+// iteration was interrupted, the best move found so far.
+func (s *Searcher) SearchContext(ctx context.Context, pos *chess.Position, limits Limits) Result {
+	s.TT.NewSearch()
+
+	s.infoLines = max(limits.MultiPV, 1)
+	if s.Skill != nil {
+		limits.MultiPV = max(limits.MultiPV, skillMultiPV)
+		if limits.Depth <= 0 || limits.Depth > s.Skill.depth() {
+			limits.Depth = s.Skill.depth()
+		}
+		if limits.Nodes == 0 || limits.Nodes > s.Skill.nodes() {
+			limits.Nodes = s.Skill.nodes()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	wait := s.startHelpers(ctx, pos, limits)
+
+	result := s.iterate(ctx, pos, limits)
+
+	// the helpers only stop when the main search does
+	cancel()
+	results := append([]Result{result}, wait()...)
+	switch {
+	case s.Skill != nil && len(s.lines) > 0:
+		result = s.Skill.pick(s.lines)
+	case limits.MultiPV <= 1:
+		result = vote(results)
+	}
+	result.Nodes = s.totalNodes()
+	return result
+}
+
+// iterate runs the iterative deepening loop of one thread
+func (s *Searcher) iterate(ctx context.Context, pos *chess.Position, limits Limits) Result {
+	s.published.Store(0)
+	s.ctx = ctx
+	s.pos = pos.Clone()
+	s.nodes = 0
+	defer func() { s.published.Store(s.nodes) }()
+	s.killers = [MaxPly][2]chess.Move{}
+	s.stack = [MaxPly]stackEntry{}
+	s.history.age()
+	s.tm = newTimeManager(limits, pos.ColorToMove(), time.Now())
+	s.stopped = false
+
+	if limits.Depth <= 0 || limits.Depth >= MaxPly {
+		limits.Depth = MaxPly - 1
+	}
+	s.limits = limits
+
+	s.rootMoves = s.pos.GenerateLegalMoves()
+	if len(limits.SearchMoves) > 0 {
+		var restricted []chess.Move
+		for _, m := range s.rootMoves {
+			if slices.Contains(limits.SearchMoves, m) {
+				restricted = append(restricted, m)
+			}
+		}
+		// ignore a restriction that leaves nothing to search
+		if len(restricted) > 0 {
+			s.rootMoves = restricted
+		}
+	}
+	multiPV := min(max(limits.MultiPV, 1), max(len(s.rootMoves), 1))
+
+	var result Result
+	var lines []Result
+	s.lines = nil
+	for depth := 1; depth <= limits.Depth; depth++ {
+		if s.thread > 0 && skipDepth(s.thread, depth) {
+			continue
+		}
+		s.rootDepth = depth
+		s.selDepth = 0
+		previous := lines
+		lines = nil
+		s.lineMoves = s.lineMoves[:0]
+
+		for s.pvIndex = 0; s.pvIndex < multiPV; s.pvIndex++ {
+			guess := result.Score
+			if s.pvIndex < len(previous) {
+				guess = previous[s.pvIndex].Score
+			}
+			score := s.aspirationSearch(depth, guess)
+			if s.stopped {
+				break
+			}
+
+			line := Result{
+				Score:    score,
+				Depth:    depth,
+				SelDepth: s.selDepth,
+				PV:       append([]chess.Move(nil), s.pvTable[0][:s.pvLength[0]]...),
+			}
+			if len(line.PV) > 0 {
+				line.Move = line.PV[0]
+				s.lineMoves = append(s.lineMoves, line.Move)
+			}
+			lines = append(lines, line)
+		}
+
+		if s.stopped {
+			// the iteration is incomplete, keep the last complete one
+			// unless there isn't one
+			if depth == 1 && len(lines) > 0 {
+				result = lines[0]
+				result.MultiPV = 1
+			} else if depth == 1 {
+				result = s.interruptedResult()
+			}
+			break
+		}
+
+		// a later line can score higher than an earlier one when the
+		// earlier one failed low within its aspiration window
+		slices.SortStableFunc(lines, func(a, b Result) int { return b.Score - a.Score })
+		for i := range lines {
+			lines[i].Nodes = s.totalNodes()
+			lines[i].Time = s.tm.elapsed()
+			lines[i].MultiPV = i + 1
+		}
+		result = lines[0]
+		s.lines = lines
+		if len(result.PV) == 0 {
+			// no legal moves, deeper iterations won't change anything
+			break
+		}
+
+		if s.Info != nil {
+			for _, line := range lines[:min(len(lines), s.infoLines)] {
+				s.Info(line)
+			}
+		}
+
+		if moves, mate := MateIn(result.Score); limits.Mate > 0 && mate && moves > 0 && moves <= limits.Mate {
+			break
+		}
+		if !s.tm.iterationDone(result) {
+			break
+		}
+	}
+
+	return result
+}
+
+// searchesRootMove reports whether m is searched at the root in the
+// current line
+func (s *Searcher) searchesRootMove(m chess.Move) bool {
+	return slices.Contains(s.rootMoves, m) && !slices.Contains(s.lineMoves, m)
+}
+
+// checkLimits stops the search when it is cancelled, or once the node
+// limit or the hard time limit is reached. The first iteration is always
+// completed unless the search is cancelled, so there is a move to play.
+// It also publishes the node count for the reports of the main thread.
+func (s *Searcher) checkLimits() {
+	s.published.Store(s.nodes)
+	if s.ctx.Err() != nil {
+		s.stopped = true
+		return
+	}
+	if s.rootDepth <= 1 {
+		return
+	}
+	if s.limits.Nodes > 0 && s.totalNodes() >= s.limits.Nodes || s.tm.hardLimitReached() {
+		s.stopped = true
+	}
+}
+
+// interruptedResult returns the best root move found by the first
+// iteration before it was stopped, or any legal move if it didn't get
+// through the first one
+func (s *Searcher) interruptedResult() Result {
+	result := Result{Depth: 1, Nodes: s.nodes, MultiPV: 1}
+	if s.pvLength[0] > 0 {
+		result.PV = append([]chess.Move(nil), s.pvTable[0][:s.pvLength[0]]...)
+	} else if len(s.rootMoves) > 0 {
+		result.PV = s.rootMoves[:1]
+	}
+	if len(result.PV) > 0 {
+		result.Move = result.PV[0]
+	}
+	return result
+}
+
+// aspirationSearch searches the root to depth with a narrow window around
+// the score of the previous iteration, which is likely to be close and
+// makes for more cutoffs. If the score falls outside the window it is
+// searched again with the window widened on that side, a little more
+// each time.
+func (s *Searcher) aspirationSearch(depth, previous int) int {
+	if depth < AspirationMinDepth {
+		return s.negamax(depth, 0, -Infinity, Infinity)
+	}
+
+	delta := AspirationWindow
+	alpha := max(previous-delta, -Infinity)
+	beta := min(previous+delta, Infinity)
+	for {
+		score := s.negamax(depth, 0, alpha, beta)
+		switch {
+		case s.stopped:
+			return score
+		case score <= alpha:
+			beta = (alpha + beta) / 2
+			alpha = max(score-delta, -Infinity)
+		case score >= beta:
+			beta = min(score+delta, Infinity)
+		default:
+			return score
+		}
+		delta += delta / 2
+	}
+}
+
+// negamax returns the score of the current position searched to depth,
+// from the side to move's point of view, and leaves the best line found in
+// the PV table. Nodes searched with a window wider than one point are PV
+// nodes, whose exact score is needed. The rest only need to establish
+// whether the score is above or below the window, which allows the
+// pruning that PV nodes don't do.
+func (s *Searcher) negamax(depth, ply, alpha, beta int) int {
+	s.pvLength[ply] = 0
+
+	if depth <= 0 || ply >= MaxPly-1 {
+		return s.quiescence(ply, 0, alpha, beta)
+	}
+	s.nodes++
+	s.selDepth = max(s.selDepth, ply)
+	if s.nodes%checkInterval == 0 || s.nodes == s.limits.Nodes {
+		s.checkLimits()
+	}
+	if s.stopped {
+		return 0
+	}
+	pvNode := beta-alpha > 1
+
+	// a drawn position scores 0 whatever the material, and must not reach
+	// the TT, where its score would be reused by the same position reached
+	// without the repetition
+	if ply > 0 && s.pos.IsDraw(ply) {
+		return 0
+	}
+
+	// Mate distance pruning: even mating at once, or being mated here,
+	// can't score better than a shorter mate already found elsewhere, so
+	// the window shrinks to the scores still possible at this ply
+	if ply > 0 {
+		alpha = max(alpha, -Mate+ply)
+		beta = min(beta, Mate-ply-1)
+		if alpha >= beta {
+			return alpha
+		}
+	}
+
+	// the move left out while checking whether the TT move is singular
+	excluded := s.stack[ply].excluded
+	singularSearch := excluded != chess.Move{}
+
+	s.stack[ply].captureTo = chess.NoSquare
+	s.stack[ply+1].extensions = s.stack[ply].extensions
+
+	var ttMove chess.Move
+	ttEntry, ttHit := s.TT.Probe(s.pos.Hash)
+	if ttHit {
+		entry := &ttEntry
+		ttMove = entry.Move()
+
+		// PV nodes always search, so that the PV is complete
+		if !pvNode && !singularSearch && entry.Depth() >= depth {
+			score := entry.Score(ply)
+			switch {
+			case entry.Bound() == BoundExact,
+				entry.Bound() == BoundLower && score >= beta,
+				entry.Bound() == BoundUpper && score <= alpha:
+				return score
+			}
+		}
+	}
+
+	inCheck := s.pos.InCheck()
+	staticEval := -Infinity
+	if !inCheck {
+		staticEval = Evaluate(s.pos)
+	}
+	// pruning that assumes the score will stay near the static evaluation
+	// is unsafe once mates are in play
+	mateBounds := alpha <= -Mate+MaxPly || beta >= Mate-MaxPly
+	// nor is it worth doing while searching for a singular move
+	prune := !pvNode && !inCheck && !mateBounds && !singularSearch
+
+	// Reverse futility pruning (static null move): if the static
+	// evaluation beats beta by a margin growing with depth, the opponent
+	// is unlikely to recover in the few plies left
+	if s.Options.ReverseFutility && prune && depth <= ReverseFutilityMaxDepth &&
+		staticEval-ReverseFutilityMargin*depth >= beta {
+		return beta
+	}
+
+	// Razoring: if the static evaluation is far below alpha close to the
+	// horizon, only a capture could bring it back, so check that with a
+	// quiescence search and give up if it can't
+	if s.Options.Razoring && prune && depth <= RazoringMaxDepth && staticEval+RazoringMargin*depth < alpha {
+		if score := s.quiescence(ply, 0, alpha, alpha+1); score <= alpha {
+			return alpha
+		}
+	}
+
+	// Null move pruning: let the opponent move twice in a row. If a reduced
+	// search still fails high, the position is good enough to cut without
+	// searching any real move. With only pawns left zugzwang is common and
+	// passing would be better than any move, so it isn't tried then, nor
+	// twice in a row.
+	if ply > 0 && prune && depth >= NullMoveMinDepth && !s.stack[ply-1].null && s.hasNonPawnMaterial() {
+		if staticEval >= beta {
+			r := NullMoveReduction + depth/NullMoveDepthDivisor +
+				min((staticEval-beta)/NullMoveEvalDivisor, NullMoveMaxEvalReduction)
+
+			s.stack[ply].null = true
+			s.pos.MakeNullMove()
+			score := -s.negamax(depth-1-r, ply+1, -beta, -beta+1)
+			s.pos.UnmakeNullMove()
+			s.stack[ply].null = false
+
+			if score >= beta {
+				// don't trust a mate found without moving
+				return beta
+			}
+		}
+	}
+
+	// ProbCut: a capture that beats beta by a margin in a shallow search
+	// will very probably beat beta in the full depth search too
+	if s.Options.ProbCut && ply > 0 && prune && depth >= ProbCutMinDepth {
+		if s.probCut(depth, ply, beta, staticEval) {
+			return beta
+		}
+	}
+
+	originalAlpha := alpha
+	var bestMove chess.Move
+	var quietsTried []chess.Move
+	legalMoves := 0
+
+	picker := newMovePicker(s.pos, ttMove, s.killers[ply], &s.history)
+	for {
+		m, ok := picker.next()
+		if !ok {
+			break
+		}
+		if m == excluded || !s.pos.IsLegal(m) || ply == 0 && !s.searchesRootMove(m) {
+			continue
+		}
+		legalMoves++
+		quiet := picker.isQuiet(m)
+		givesCheck := s.pos.GivesCheck(m)
+
+		// Futility pruning: near the horizon a quiet move can't gain much,
+		// so if even a generous margin over the static evaluation doesn't
+		// reach alpha the move isn't searched
+		if s.Options.Futility && prune && quiet && !givesCheck &&
+			legalMoves > 1 && depth <= FutilityMaxDepth && staticEval+FutilityMargin*depth <= alpha {
+			continue
+		}
+
+		if ply == 0 && s.CurrMove != nil && s.tm.elapsed() >= currMoveDelay {
+			s.CurrMove(depth, m, s.pvIndex+legalMoves)
+		}
+
+		extension := s.extension(m, ply, depth, pvNode, givesCheck, ttEntry, ttHit)
+		newDepth := depth - 1 + extension
+
+		s.stack[ply+1].extensions = s.stack[ply].extensions + extension
+		if capture := s.pos.PieceMap[m.To] != 0; capture {
+			s.stack[ply].captureTo = m.To
+		} else {
+			s.stack[ply].captureTo = chess.NoSquare
+		}
+
+		s.pos.MakeMove(m)
+
+		// Late move reductions: with good ordering, quiet moves late in the
+		// list rarely turn out best, so they are searched less deep first
+		// and only searched again at full depth if they beat alpha
+		reduction := 0
+		if depth >= LMRMinDepth && legalMoves > LMRMinMoves && quiet && !inCheck && extension == 0 {
+			reduction = lateMoveReduction(depth, legalMoves)
+			if pvNode {
+				reduction--
+			}
+			if m == s.killers[ply][0] || m == s.killers[ply][1] {
+				reduction--
+			}
+			reduction = min(max(reduction, 0), newDepth-1)
+		}
+
+		// Principal variation search: once a move has been searched, the
+		// rest are expected to be worse and only have to be proven so
+		// with a zero window search. One that turns out better is
+		// searched again, at full depth if it was reduced and then with
+		// the full window at PV nodes to get its exact score.
+		var score int
+		if legalMoves == 1 {
+			score = -s.negamax(newDepth, ply+1, -beta, -alpha)
+		} else {
+			score = -s.negamax(newDepth-reduction, ply+1, -alpha-1, -alpha)
+			if score > alpha && reduction > 0 {
+				score = -s.negamax(newDepth, ply+1, -alpha-1, -alpha)
+			}
+			if score > alpha && score < beta {
+				score = -s.negamax(newDepth, ply+1, -beta, -alpha)
+			}
+		}
+		s.pos.UnmakeMove()
+
+		if s.stopped {
+			return 0
+		}
+
+		if score > alpha {
+			alpha = score
+			bestMove = m
+			s.updatePV(ply, m)
+			if alpha >= beta {
+				if quiet {
+					s.updateQuietStats(ply, depth, m, quietsTried)
+				}
+				break
+			}
+		}
+		if quiet {
+			quietsTried = append(quietsTried, m)
+		}
+	}
+
+	if legalMoves == 0 {
+		if singularSearch {
+			// the excluded move was the only one
+			return alpha
+		}
+		if inCheck {
+			return -Mate + ply
+		}
+		return 0
+	}
+
+	bound := BoundUpper
+	if alpha >= beta {
+		bound = BoundLower
+	} else if alpha > originalAlpha {
+		bound = BoundExact
+	}
+	// later MultiPV lines leave the best moves out, so their result isn't
+	// the position's
+	if !singularSearch && !(ply == 0 && s.pvIndex > 0) {
+		s.TT.Store(s.pos.Hash, bestMove, alpha, depth, ply, bound)
+	}
+
+	return alpha
+}
+
+// updateQuietStats rewards a quiet move that caused a beta cutoff, making
+// it a killer at this ply and raising its history, and penalises the quiet
+// moves searched before it
+func (s *Searcher) updateQuietStats(ply, depth int, move chess.Move, quietsTried []chess.Move) {
+	if s.killers[ply][0] != move {
+		s.killers[ply][1] = s.killers[ply][0]
+		s.killers[ply][0] = move
+	}
+
+	color := s.pos.ColorToMove()
+	bonus := depth * depth
+	s.history.update(color, move, bonus)
+	for _, m := range quietsTried {
+		s.history.update(color, m, -bonus)
+	}
+}
+
+// hasNonPawnMaterial reports whether the side to move has any piece other
+// than pawns and the king
+func (s *Searcher) hasNonPawnMaterial() bool {
+	us := s.pos.ColorToMove()
+	return s.pos.PiecesOfColor(us)&^(s.pos.PiecesOfType(us, chess.Pawn)|s.pos.PiecesOfType(us, chess.King)) != 0
+}
+
+// updatePV makes move followed by the PV of the next ply the PV at ply
+func (s *Searcher) updatePV(ply int, move chess.Move) {
+	s.pvTable[ply][0] = move
+	n := copy(s.pvTable[ply][1:], s.pvTable[ply+1][:s.pvLength[ply+1]])
+	s.pvLength[ply] = n + 1
+}
+
+// probCut searches the captures that could beat beta by ProbCutMargin,
+// first with a quiescence search and then to a reduced depth, and reports
+// whether one of them did
+func (s *Searcher) probCut(depth, ply, beta, staticEval int) bool {
+	probBeta := beta + ProbCutMargin
+
+	moves := s.pos.GenerateCaptures()
+	orderCaptures(s.pos, moves)
+	for _, m := range moves {
+		if !s.pos.IsLegal(m) || s.pos.SEE(m) < probBeta-staticEval {
+			continue
+		}
+
+		s.pos.MakeMove(m)
+		score := -s.quiescence(ply+1, 0, -probBeta, -probBeta+1)
+		if score >= probBeta {
+			score = -s.negamax(depth-1-ProbCutReduction, ply+1, -probBeta, -probBeta+1)
+		}
+		s.pos.UnmakeMove()
+
+		if score >= probBeta {
+			return true
+		}
+	}
+	return false
+}
+
+// extension returns how many plies deeper than usual to search move:
+//   - moves that give check, which often lead to forced sequences
+//   - the TT move if it is singular, i.e. much better than any other move
+//     according to a reduced search that leaves it out, since the line
+//     then hinges on it
+//   - recaptures on the square just captured on at PV nodes, so exchanges
+//     aren't cut off halfway
+//
+// A line is extended by at most the depth of the iteration in total, so
+// the search can't be extended forever.
+func (s *Searcher) extension(m chess.Move, ply, depth int, pvNode, givesCheck bool, ttEntry TTEntry, ttHit bool) int {
+	if s.stack[ply].extensions >= s.rootDepth {
+		return 0
+	}
+
+	if ply > 0 && ttHit && m == ttEntry.Move() && depth >= SingularMinDepth && s.stack[ply].excluded == (chess.Move{}) &&
+		ttEntry.Bound() != BoundUpper && ttEntry.Depth() >= depth-SingularDepthMargin {
+		ttScore := ttEntry.Score(ply)
+		if ttScore > -Mate+MaxPly && ttScore < Mate-MaxPly {
+			singularBeta := ttScore - SingularMargin*depth
+
+			s.stack[ply].excluded = m
+			score := s.negamax((depth-1)/2, ply, singularBeta-1, singularBeta)
+			s.stack[ply].excluded = chess.Move{}
+			// the excluded search left its own line in the PV table at
+			// this ply, which isn't a line of this node
+			s.pvLength[ply] = 0
+
+			if score < singularBeta {
+				return 1
+			}
+		}
+	}
+
+	if givesCheck {
+		return 1
+	}
+
+	if pvNode && ply > 0 && s.stack[ply-1].captureTo == m.To && s.pos.PieceMap[m.To] != 0 {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,462 @@
+package search
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/liam-hatcher/gohobbyengine/chess"
+)
+
+func mustParseFEN(t testing.TB, fen string) *chess.Position {
+	t.Helper()
+	pos, err := chess.NewPositionFromFEN(fen)
+	if err != nil {
+		t.Fatalf("parsing %q: %v", fen, err)
+	}
+	return pos
+}
+
+func TestEvaluateIsSymmetric(t *testing.T) {
+	if score := Evaluate(chess.NewPosition()); score != 0 {
+		t.Errorf("expected the start position to score 0, got %d", score)
+	}
+
+	// the same position with colors swapped and the board mirrored
+	white := mustParseFEN(t, "r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3")
+	black := mustParseFEN(t, "rnbqkb1r/pppp1ppp/5n2/4p3/4P3/2N5/PPPP1PPP/R1BQKBNR b KQkq - 2 3")
+	if Evaluate(white) != Evaluate(black) {
+		t.Errorf("expected mirrored positions to score the same, got %d and %d", Evaluate(white), Evaluate(black))
+	}
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		depth    int
+		expected string
+	}{
+		{"back rank mate", "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", 2, "a1a8"},
+		{"mate for black", "6k1/8/8/8/8/8/1r3PPP/6K1 b - - 0 1", 2, "b2b1"},
+		{"win the hanging queen", "4k3/8/8/3q4/8/8/8/3RK3 w - - 0 1", 2, "d1d5"},
+		{"avoid the poisoned pawn", "4k3/3r4/8/3p4/8/8/8/3QK3 w - - 0 1", 1, ""},
+	}
+
+	for _, tt := range tests {
+		result := NewSearcher().Search(mustParseFEN(t, tt.fen), Limits{Depth: tt.depth})
+		got := chess.ToUCINotation(result.Move)
+		if tt.expected != "" && got != tt.expected {
+			t.Errorf("%s: expected %s, got %s (score %d)", tt.name, tt.expected, got, result.Score)
+		}
+		if tt.expected == "" && got == "d1d5" {
+			t.Errorf("%s: expected the queen not to take the defended pawn", tt.name)
+		}
+	}
+}
+
+func TestSearchReportsMate(t *testing.T) {
+	result := NewSearcher().Search(mustParseFEN(t, "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1"), Limits{Depth: 3})
+	if result.Score != Mate-1 {
+		t.Errorf("expected mate in 1 to score %d, got %d", Mate-1, result.Score)
+	}
+	if len(result.PV) == 0 || result.PV[0] != result.Move {
+		t.Errorf("expected the PV to start with the best move, got %v", result.PV)
+	}
+}
+
+func TestSearchWithoutLegalMoves(t *testing.T) {
+	tests := []struct {
+		name     string
+		fen      string
+		expected int
+	}{
+		{"checkmate", "R5k1/5ppp/8/8/8/8/8/6K1 b - - 1 1", -Mate},
+		{"stalemate", "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", 0},
+	}
+
+	for _, tt := range tests {
+		result := NewSearcher().Search(mustParseFEN(t, tt.fen), Limits{Depth: 3})
+		if result.Score != tt.expected || len(result.PV) != 0 {
+			t.Errorf("%s: expected score %d and no PV, got %d and %v", tt.name, tt.expected, result.Score, result.PV)
+		}
+	}
+}
+
+func TestSearchLeavesPositionUntouched(t *testing.T) {
+	pos := mustParseFEN(t, "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	hash := pos.Hash
+	NewSearcher().Search(pos, Limits{Depth: 2})
+	if pos.Hash != hash || pos.Hash != pos.ComputeHash() {
+		t.Error("expected the search to leave the position untouched")
+	}
+}
+
+func BenchmarkBench(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Bench(4, nil)
+	}
+}
+
+func TestSearchFindsMateInTwo(t *testing.T) {
+	pos := mustParseFEN(t, "r2qkb1r/pp2nppp/3p4/2pNN1B1/2BnP3/3P4/PPP2PPP/R2bK2R w KQkq - 1 10")
+	result := NewSearcher().Search(pos, Limits{Depth: 4})
+	if got := chess.ToUCINotation(result.Move); got != "d5f6" || result.Score != Mate-3 {
+		t.Errorf("expected d5f6 mating in 3 plies, got %s with score %d", got, result.Score)
+	}
+}
+
+func TestSearchFindsGettingMated(t *testing.T) {
+	// Kb8 is forced, then Rh8 mates; searching deeper doesn't change that
+	pos := mustParseFEN(t, "k7/8/1K6/8/8/8/8/7R b - - 0 1")
+	for _, depth := range []int{3, 8} {
+		result := NewSearcher().Search(pos, Limits{Depth: depth})
+		if got := chess.ToUCINotation(result.Move); got != "a8b8" || result.Score != -Mate+2 {
+			t.Errorf("depth %d: expected a8b8 getting mated in 2 plies, got %s with score %d", depth, got, result.Score)
+		}
+	}
+}
+
+func TestMateDistancePruning(t *testing.T) {
+	// once the mate in 2 is known, deeper iterations can skip lines that
+	// couldn't mate any faster
+	pos := mustParseFEN(t, "r2qkb1r/pp2nppp/3p4/2pNN1B1/2BnP3/3P4/PPP2PPP/R2bK2R w KQkq - 1 10")
+	s := NewSearcher()
+	result := s.Search(pos, Limits{Depth: 8})
+	if result.Score != Mate-3 {
+		t.Errorf("expected the mate in 3 plies to be kept, got %d", result.Score)
+	}
+
+	// a window no mate at ply 2 can beat is cut off at once
+	s.pos = pos.Clone()
+	s.ctx = context.Background()
+	s.tm = newTimeManager(Limits{}, chess.White, time.Now())
+	if score := s.negamax(4, 2, Mate-2, Mate-1); score != Mate-2 {
+		t.Errorf("expected the window to be cut to alpha, got %d", score)
+	}
+}
+
+func TestSearchTakesPerpetualCheck(t *testing.T) {
+	// Black mates on the back rank unless White keeps checking with
+	// Qe8+ Kh7 Qh5+ Kg8, which only a repetition can stop
+	pos := mustParseFEN(t, "6k1/3Q2p1/8/8/ppp5/8/rr3PPP/6K1 w - - 0 1")
+	result := NewSearcher().Search(pos, Limits{Depth: 10})
+	if got := chess.ToUCINotation(result.Move); got != "d7e8" || result.Score != 0 {
+		t.Errorf("expected d7e8 drawing by perpetual check, got %s with score %d", got, result.Score)
+	}
+}
+
+func TestSearchAvoidsRepetitionWhenWinning(t *testing.T) {
+	// Ra1 is the best move, but the position after it has been seen twice
+	// already, so playing it again would draw
+	pos := mustParseFEN(t, "8/4kppp/8/8/8/8/5PPP/3R2K1 w - - 0 1")
+	for i := 0; i < 2; i++ {
+		for _, move := range []string{"d1a1", "e7e8", "a1d1", "e8e7"} {
+			pos.ApplyMove(move)
+		}
+	}
+	result := NewSearcher().Search(pos, Limits{Depth: 9})
+	if got := chess.ToUCINotation(result.Move); got == "d1a1" || result.Score <= 0 {
+		t.Errorf("expected a move other than d1a1 keeping the advantage, got %s with score %d", got, result.Score)
+	}
+}
+
+func TestMateIn(t *testing.T) {
+	tests := []struct {
+		score int
+		moves int
+		mate  bool
+	}{
+		{Mate - 1, 1, true},
+		{Mate - 3, 2, true},
+		{Mate - 4, 2, true},
+		{-Mate, 0, true},
+		{-Mate + 2, -1, true},
+		{-Mate + 5, -2, true},
+		{350, 0, false},
+		{-Mate + MaxPly + 1, 0, false},
+	}
+
+	for _, tt := range tests {
+		moves, mate := MateIn(tt.score)
+		if moves != tt.moves || mate != tt.mate {
+			t.Errorf("%d: expected %d %v, got %d %v", tt.score, tt.moves, tt.mate, moves, mate)
+		}
+	}
+}
+
+func TestHasNonPawnMaterial(t *testing.T) {
+	tests := []struct {
+		fen      string
+		expected bool
+	}{
+		{chess.StartFEN, true},
+		{"4k3/pppp4/8/8/8/8/4PPPP/4K3 w - - 0 1", false},
+		{"4k3/pppp4/8/8/8/8/4PPPP/4KN2 b - - 0 1", false},
+		{"4k3/pppp4/8/8/8/8/4PPPP/4KN2 w - - 0 1", true},
+	}
+
+	for _, tt := range tests {
+		s := NewSearcher()
+		s.pos = mustParseFEN(t, tt.fen)
+		if got := s.hasNonPawnMaterial(); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.fen, tt.expected, got)
+		}
+	}
+}
+
+func TestLateMoveReductions(t *testing.T) {
+	if lateMoveReduction(1, 1) != 0 {
+		t.Errorf("expected no reduction for the first move, got %d", lateMoveReduction(1, 1))
+	}
+	for depth := 2; depth < 20; depth++ {
+		for moves := 2; moves < 60; moves++ {
+			r := lateMoveReduction(depth, moves)
+			if r < lateMoveReduction(depth-1, moves) || r < lateMoveReduction(depth, moves-1) {
+				t.Fatalf("expected reductions to grow with depth and move number, depth %d moves %d", depth, moves)
+			}
+		}
+	}
+}
+
+func TestSearchPVIsLegal(t *testing.T) {
+	for _, fen := range BenchPositions {
+		pos := mustParseFEN(t, fen)
+		result := NewSearcher().Search(pos, Limits{Depth: 5})
+		if len(result.PV) == 0 {
+			t.Errorf("%s: expected a PV", fen)
+			continue
+		}
+
+		for _, m := range result.PV {
+			if !pos.IsPseudoLegal(m) || !pos.IsLegal(m) {
+				t.Errorf("%s: PV %v has illegal move %s", fen, result.PV, chess.ToUCINotation(m))
+				break
+			}
+			pos.MakeMove(m)
+		}
+	}
+}
+
+func TestSearchReportsEachIteration(t *testing.T) {
+	s := NewSearcher()
+	var depths []int
+	s.Info = func(r Result) {
+		depths = append(depths, r.Depth)
+		if len(r.PV) == 0 || r.PV[0] != r.Move {
+			t.Errorf("depth %d: expected the PV to start with the best move", r.Depth)
+		}
+		if r.SelDepth < r.Depth || r.SelDepth < len(r.PV) {
+			t.Errorf("depth %d: expected a selective depth of at least the PV, got %d", r.Depth, r.SelDepth)
+		}
+	}
+	s.CurrMove = func(int, chess.Move, int) {
+		t.Error("expected no currmove reports from a short search")
+	}
+
+	s.Search(chess.NewPosition(), Limits{Depth: 4})
+	if len(depths) != 4 || depths[0] != 1 || depths[3] != 4 {
+		t.Errorf("expected an info for each of depths 1 to 4, got %v", depths)
+	}
+}
+
+func TestSearchReportsCurrMove(t *testing.T) {
+	defer func(delay time.Duration) { currMoveDelay = delay }(currMoveDelay)
+	currMoveDelay = 50 * time.Millisecond
+
+	s := NewSearcher()
+	var numbers []int
+	s.CurrMove = func(depth int, move chess.Move, number int) {
+		numbers = append(numbers, number)
+	}
+	s.Search(chess.NewPosition(), Limits{MoveTime: 500 * time.Millisecond})
+
+	if len(numbers) == 0 {
+		t.Fatal("expected currmove reports once the delay has passed")
+	}
+	for _, n := range numbers {
+		if n < 1 || n > 20 {
+			t.Errorf("expected move numbers within the 20 root moves, got %d", n)
+		}
+	}
+}
+
+func TestMultiPV(t *testing.T) {
+	s := NewSearcher()
+	lines := map[int][]Result{}
+	s.Info = func(r Result) {
+		lines[r.Depth] = append(lines[r.Depth], r)
+	}
+
+	result := s.Search(chess.NewPosition(), Limits{Depth: 4, MultiPV: 3})
+	for depth := 1; depth <= 4; depth++ {
+		got := lines[depth]
+		if len(got) != 3 {
+			t.Fatalf("depth %d: expected 3 lines, got %d", depth, len(got))
+		}
+		seen := map[chess.Move]bool{}
+		for i, line := range got {
+			if line.MultiPV != i+1 || seen[line.Move] || len(line.PV) == 0 || line.PV[0] != line.Move {
+				t.Errorf("depth %d: unexpected line %d %+v", depth, i+1, line)
+			}
+			if i > 0 && line.Score > got[i-1].Score {
+				t.Errorf("depth %d: expected lines sorted by score, got %d after %d", depth, line.Score, got[i-1].Score)
+			}
+			seen[line.Move] = true
+		}
+	}
+	if result.Move != lines[4][0].Move || result.MultiPV != 1 {
+		t.Errorf("expected the best line as the result, got %s", chess.ToUCINotation(result.Move))
+	}
+
+	// more lines than legal moves: just one for each move
+	lines = map[int][]Result{}
+	s.Search(mustParseFEN(t, "7k/8/8/8/8/8/8/K7 w - - 0 1"), Limits{Depth: 2, MultiPV: 10})
+	if len(lines[2]) != 3 {
+		t.Errorf("expected a line for each of the king's 3 moves, got %d", len(lines[2]))
+	}
+}
+
+func TestSearchMoves(t *testing.T) {
+	// Qxf7 mates, but only the pawn moves may be searched
+	pos := mustParseFEN(t, "r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4")
+	a2a3 := mustParseMove(t, "a2a3")
+	h2h4 := mustParseMove(t, "h2h4")
+
+	result := NewSearcher().Search(pos, Limits{Depth: 3, SearchMoves: []chess.Move{a2a3, h2h4}})
+	if result.Move != a2a3 && result.Move != h2h4 {
+		t.Errorf("expected a2a3 or h2h4, got %s", chess.ToUCINotation(result.Move))
+	}
+
+	// illegal moves are ignored, and with nothing left so is the restriction
+	result = NewSearcher().Search(pos, Limits{Depth: 3, SearchMoves: []chess.Move{mustParseMove(t, "e1e3")}})
+	if chess.ToUCINotation(result.Move) != "h5f7" {
+		t.Errorf("expected h5f7, got %s", chess.ToUCINotation(result.Move))
+	}
+}
+
+func TestForwardPruningOptions(t *testing.T) {
+	configurations := map[string]Options{
+		"none":             {},
+		"reverse futility": {ReverseFutility: true},
+		"futility":         {Futility: true},
+		"razoring":         {Razoring: true},
+		"probcut":          {ProbCut: true},
+		"default":          DefaultOptions,
+	}
+	tests := []struct {
+		fen      string
+		expected string
+	}{
+		{"r2qkb1r/pp2nppp/3p4/2pNN1B1/2BnP3/3P4/PPP2PPP/R2bK2R w KQkq - 1 10", "d5f6"},
+		{"4k3/8/8/3q4/8/8/8/3RK3 w - - 0 1", "d1d5"},
+		{"r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4", "h5f7"},
+	}
+
+	for name, options := range configurations {
+		for _, tt := range tests {
+			s := NewSearcher()
+			s.Options = options
+			result := s.Search(mustParseFEN(t, tt.fen), Limits{Depth: 5})
+			if got := chess.ToUCINotation(result.Move); got != tt.expected {
+				t.Errorf("%s: %s: expected %s, got %s", name, tt.fen, tt.expected, got)
+			}
+		}
+	}
+
+	nodes := func(options Options) uint64 {
+		s := NewSearcher()
+		s.Options = options
+		return s.Search(mustParseFEN(t, BenchPositions[1]), Limits{Depth: 6}).Nodes
+	}
+	if pruned, full := nodes(DefaultOptions), nodes(Options{}); pruned >= full {
+		t.Errorf("expected pruning to shrink the tree, searched %d nodes with it and %d without", pruned, full)
+	}
+}
+
+func TestCheckExtensionFindsDeeperMates(t *testing.T) {
+	// mate in 2 by checks, found by a depth 1 search since each check
+	// gets an extra ply
+	pos := mustParseFEN(t, "r2qkb1r/pp2nppp/3p4/2pNN1B1/2BnP3/3P4/PPP2PPP/R2bK2R w KQkq - 1 10")
+	result := NewSearcher().Search(pos, Limits{Depth: 1})
+	if result.Score != Mate-3 {
+		t.Errorf("expected the mate in 3 plies to be found at depth 1, got score %d", result.Score)
+	}
+}
+
+// singularSearcher returns a searcher set up to call extension at ply 1 of
+// a depth 10 iteration in pos, and a TT entry for move with the score a
+// search gives it
+func singularSearcher(t *testing.T, fen, uci string) (*Searcher, chess.Move, TTEntry) {
+	t.Helper()
+	pos := mustParseFEN(t, fen)
+	move := mustParseMove(t, uci)
+	score := NewSearcher().Search(pos, Limits{Depth: 6}).Score
+
+	s := NewSearcher()
+	s.pos = pos.Clone()
+	s.ctx = context.Background()
+	s.tm = newTimeManager(Limits{}, pos.ColorToMove(), time.Now())
+	s.rootDepth = 10
+	entry := TTEntry{move: packMove(move), score: int16(score), depth: 6, bound: BoundLower}
+	return s, move, entry
+}
+
+func TestSingularExtension(t *testing.T) {
+	// only the rook can take the queen, anything else leaves white a
+	// queen for a rook down
+	const singular = "4k3/8/8/3q4/8/8/8/3RK3 w - - 0 1"
+
+	s, move, entry := singularSearcher(t, singular, "d1d5")
+	if ext := s.extension(move, 1, 6, false, false, entry, true); ext != 1 {
+		t.Errorf("expected the singular move to be extended at depth 6, got %d", ext)
+	}
+	if ext := s.extension(move, 1, 8, false, false, entry, true); ext != 1 {
+		t.Errorf("expected the singular move to be extended at depth 8, got %d", ext)
+	}
+	if ext := s.extension(move, 1, SingularMinDepth-1, false, false, entry, true); ext != 0 {
+		t.Errorf("expected no singular search below depth %d, got %d", SingularMinDepth, ext)
+	}
+	if s.stack[1].excluded != (chess.Move{}) || s.pvLength[1] != 0 {
+		t.Error("expected the excluded search to leave no excluded move or PV behind")
+	}
+
+	// the extensions so far use up the budget of the iteration
+	s.stack[1].extensions = s.rootDepth
+	if ext := s.extension(move, 1, 6, false, false, entry, true); ext != 0 {
+		t.Errorf("expected no extension past the budget, got %d", ext)
+	}
+	if ext := s.extension(move, 1, 6, true, true, entry, true); ext != 0 {
+		t.Errorf("expected no check extension past the budget either, got %d", ext)
+	}
+	s.stack[1].extensions = s.rootDepth - 1
+	if ext := s.extension(move, 1, 6, false, false, entry, true); ext != 1 {
+		t.Errorf("expected the last ply of the budget to be used, got %d", ext)
+	}
+
+	// the knight can take the queen just as well
+	s, move, entry = singularSearcher(t, "4k3/8/8/3q4/8/2N5/8/3RK3 w - - 0 1", "d1d5")
+	if ext := s.extension(move, 1, 6, false, false, entry, true); ext != 0 {
+		t.Errorf("expected a move with an equal alternative not to be extended, got %d", ext)
+	}
+}
+
+func TestRecaptureExtension(t *testing.T) {
+	s := NewSearcher()
+	// white has just taken a knight on e5
+	s.pos = mustParseFEN(t, "4k3/8/3p4/4N3/8/8/8/4K3 b - - 0 1")
+	recapture := mustParseMove(t, "d6e5")
+	other := mustParseMove(t, "e8d8")
+	s.rootDepth = 4
+
+	s.stack[0].captureTo = recapture.To
+	if ext := s.extension(recapture, 1, 4, true, false, TTEntry{}, false); ext != 1 {
+		t.Errorf("expected the recapture at a PV node to be extended, got %d", ext)
+	}
+	if ext := s.extension(recapture, 1, 4, false, false, TTEntry{}, false); ext != 0 {
+		t.Errorf("expected the recapture at a non-PV node not to be extended, got %d", ext)
+	}
+	if ext := s.extension(other, 1, 4, true, false, TTEntry{}, false); ext != 0 {
+		t.Errorf("expected a quiet move not to be extended, got %d", ext)
+	}
+}
@@ -0,0 +1,92 @@
+package search
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/liam-hatcher/gohobbyengine/chess"
+)
+
+// Skill levels run from 0, the weakest, to MaxSkillLevel, full strength
+const MaxSkillLevel = 20
+
+// The Elo range UCI_Elo is offered in. Levels are converted from Elo with
+// the fit Stockfish uses for its own levels, so for this engine the
+// ratings are only approximate.
+const (
+	MinSkillElo = 1320
+	MaxSkillElo = 3190
+)
+
+// the number of lines a weakened search considers
+const skillMultiPV = 4
+
+// the node limit of level 0, which doubles every two levels
+const skillBaseNodes = 4096
+
+// Skill weakens the search to give people a fair game. A weakened search
+// stops early, at a depth and a node count that grow with the level, so
+// that it plays the same on fast hardware as on slow, and searches several
+// lines, see skillMultiPV. It then plays one of them at random, favouring
+// the better moves, and the more so the higher the level. The mistakes
+// made this way are of the kind a player makes: a plausible move that
+// misses something, rather than a random one.
+type Skill struct {
+	level float64
+	rng   *rand.Rand
+}
+
+// NewSkill returns a Skill for level, which is clamped to 0 to
+// MaxSkillLevel, or nil for full strength
+func NewSkill(level float64) *Skill {
+	level = min(max(level, 0), MaxSkillLevel)
+	if level >= MaxSkillLevel {
+		return nil
+	}
+	return &Skill{level: level, rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// SkillFromElo returns a Skill playing at roughly elo, which is clamped to
+// MinSkillElo to MaxSkillElo
+func SkillFromElo(elo int) *Skill {
+	e := float64(min(max(elo, MinSkillElo), MaxSkillElo))
+	level := math.Pow(max((e-1346.6)/143.4, 0), 1/0.806)
+	return NewSkill(min(max(level, 0), MaxSkillLevel-1))
+}
+
+// Level returns the skill level, which may be fractional when converted
+// from Elo
+func (sk *Skill) Level() float64 { return sk.level }
+
+// depth returns the last iteration searched
+func (sk *Skill) depth() int {
+	return 1 + int(sk.level)
+}
+
+// nodes returns the most nodes searched, summed over all threads
+func (sk *Skill) nodes() uint64 {
+	return uint64(skillBaseNodes * math.Pow(2, sk.level/2))
+}
+
+// pick chooses the line to play from lines, sorted best first. Each line
+// gets a bonus made up of a share of its gap to the best line, so weaker
+// lines are pushed towards the top, and a random part of up to a pawn; the
+// line with the highest score plus bonus is played. Both parts grow as the
+// level drops.
+func (sk *Skill) pick(lines []Result) Result {
+	top := lines[0].Score
+	delta := min(top-lines[len(lines)-1].Score, pieceValues[chess.Pawn])
+	weakness := 120 - 2*sk.level
+
+	best := lines[0]
+	maxScore := math.Inf(-1)
+	for _, line := range lines {
+		push := (weakness*float64(top-line.Score) + float64(delta)*sk.rng.Float64()*weakness) / 128
+		if score := float64(line.Score) + push; score >= maxScore {
+			maxScore = score
+			best = line
+		}
+	}
+	return best
+}
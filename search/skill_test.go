@@ -0,0 +1,112 @@
+package search
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/liam-hatcher/gohobbyengine/chess"
+)
+
+func TestSkillFromElo(t *testing.T) {
+	if sk := SkillFromElo(MinSkillElo - 100); sk == nil || sk.Level() != 0 {
+		t.Errorf("expected the lowest Elo to be level 0, got %v", sk)
+	}
+	if sk := SkillFromElo(MaxSkillElo); sk == nil || sk.Level() != MaxSkillLevel-1 {
+		t.Errorf("expected the highest Elo to be the highest weakened level, got %v", sk)
+	}
+
+	previous := -1.0
+	for elo := MinSkillElo; elo <= MaxSkillElo; elo += 100 {
+		level := SkillFromElo(elo).Level()
+		if level < previous {
+			t.Errorf("%d: expected the level to grow with Elo, got %.2f after %.2f", elo, level, previous)
+		}
+		previous = level
+	}
+
+	if NewSkill(MaxSkillLevel) != nil || NewSkill(MaxSkillLevel+5) != nil {
+		t.Error("expected full strength not to be weakened")
+	}
+}
+
+func TestSkillPick(t *testing.T) {
+	var lines []Result
+	for i, uci := range []string{"e2e4", "d2d4", "g1f3", "a2a3"} {
+		move := mustParseMove(t, uci)
+		lines = append(lines, Result{Move: move, Score: 50 - 30*i, PV: []chess.Move{move}})
+	}
+
+	// how often a level plays something other than the best line
+	mistakes := func(level float64) int {
+		sk := NewSkill(level)
+		sk.rng = rand.New(rand.NewSource(1))
+		n := 0
+		for i := 0; i < 1000; i++ {
+			if sk.pick(lines).Move != lines[0].Move {
+				n++
+			}
+		}
+		return n
+	}
+
+	weakest, strong := mistakes(0), mistakes(19)
+	if weakest == 0 {
+		t.Error("expected level 0 to make mistakes")
+	}
+	if strong >= weakest {
+		t.Errorf("expected level 19 to make fewer mistakes than level 0, got %d and %d", strong, weakest)
+	}
+}
+
+func TestSkillLimitsSearch(t *testing.T) {
+	s := NewSearcher()
+	s.Skill = NewSkill(3)
+	reported := map[int]int{}
+	s.Info = func(r Result) {
+		reported[r.Depth]++
+	}
+
+	result := s.Search(chess.NewPosition(), Limits{Depth: 10})
+	if len(reported) != 4 || reported[4] != 1 {
+		t.Errorf("expected one line reported for each of depths 1 to 4, got %v", reported)
+	}
+
+	// the extra lines are searched all the same, and one of them played
+	if len(s.lines) != skillMultiPV {
+		t.Fatalf("expected %d lines searched, got %d", skillMultiPV, len(s.lines))
+	}
+	played := false
+	for _, line := range s.lines {
+		played = played || line.Move == result.Move
+	}
+	if !played {
+		t.Errorf("expected one of the lines to be played, got %s", chess.ToUCINotation(result.Move))
+	}
+
+	// asking for lines reports that many
+	reported = map[int]int{}
+	s.Search(chess.NewPosition(), Limits{Depth: 10, MultiPV: 2})
+	if reported[4] != 2 {
+		t.Errorf("expected 2 lines reported at depth 4, got %d", reported[4])
+	}
+}
+
+func TestSkillLimitsNodes(t *testing.T) {
+	// the depth limit alone would take far more nodes than level 5 allows
+	s := NewSearcher()
+	s.Skill = NewSkill(5)
+	pos := mustParseFEN(t, "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+
+	result := s.Search(pos, Limits{})
+	if result.Nodes < s.Skill.nodes() || result.Nodes > s.Skill.nodes()+checkInterval {
+		t.Errorf("expected the search to stop at %d nodes, got %d", s.Skill.nodes(), result.Nodes)
+	}
+	if result.Depth >= s.Skill.depth() {
+		t.Errorf("expected the node limit to stop the search before depth %d", s.Skill.depth())
+	}
+
+	// a lower limit asked for still applies
+	if result := s.Search(pos, Limits{Nodes: 10000}); result.Nodes > 10000+checkInterval {
+		t.Errorf("expected the search to stop at 10000 nodes, got %d", result.Nodes)
+	}
+}
@@ -0,0 +1,131 @@
+package search
+
+import (
+	"context"
+	"sync"
+
+	"github.com/liam-hatcher/gohobbyengine/chess"
+)
+
+// Lazy SMP: the helper threads run the same iterative deepening search as
+// the main thread, each with its own position, history and killers, and
+// share only the transposition table. Their results mostly help through
+// the table, which fills with positions the main thread reaches later, and
+// the helpers start their iterations at different depths so they don't
+// all search the same tree at the same time.
+
+// MaxThreads bounds Searcher.Threads
+const MaxThreads = 256
+
+// the depths a helper skips repeat in blocks: helper i skips blocks of
+// skipSize[i] depths, starting skipPhase[i] depths in
+var (
+	skipSize  = [...]int{1, 1, 2, 2, 2, 2, 3, 3, 3, 3, 3, 3, 4, 4, 4, 4, 4, 4, 4, 4}
+	skipPhase = [...]int{0, 1, 0, 1, 2, 3, 0, 1, 2, 3, 4, 5, 0, 1, 2, 3, 4, 5, 6, 7}
+)
+
+// skipDepth reports whether helper thread leaves out the iteration at
+// depth. The first iteration is never skipped so every thread has a move.
+func skipDepth(thread, depth int) bool {
+	if depth == 1 {
+		return false
+	}
+	i := (thread - 1) % len(skipSize)
+	return (depth+skipPhase[i])/skipSize[i]%2 != 0
+}
+
+// startHelpers starts a search of pos by each helper thread, which runs
+// until ctx is cancelled or it reaches the depth limit. The function it
+// returns waits for them and returns their results.
+func (s *Searcher) startHelpers(ctx context.Context, pos *chess.Position, limits Limits) func() []Result {
+	threads := min(max(s.Threads, 1), MaxThreads)
+	for len(s.helpers) < threads-1 {
+		s.helpers = append(s.helpers, &Searcher{main: s, thread: len(s.helpers) + 1})
+	}
+	s.helpers = s.helpers[:threads-1]
+
+	// the main thread handles the clock and the other limits, and reports.
+	// The helpers check the node limit against the nodes of all threads
+	// too, or they could run far past it while the main thread waits to
+	// be scheduled.
+	helperLimits := Limits{Depth: limits.Depth, Nodes: limits.Nodes, SearchMoves: limits.SearchMoves}
+
+	// the helpers read the main thread's count from the start
+	s.published.Store(0)
+
+	results := make([]Result, len(s.helpers))
+	var wg sync.WaitGroup
+	for i, h := range s.helpers {
+		h.Options, h.TT = s.Options, s.TT
+		// the count left from the last search would otherwise be added
+		// in until the helper first publishes
+		h.published.Store(0)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.iterate(ctx, pos, helperLimits)
+		}()
+	}
+
+	return func() []Result {
+		wg.Wait()
+		return results
+	}
+}
+
+// totalNodes returns the number of nodes searched by all threads so far.
+// A helper sees the counts of all threads as last published, its own
+// included.
+func (s *Searcher) totalNodes() uint64 {
+	if s.main != nil {
+		return s.main.published.Load() + s.main.helperNodes()
+	}
+	return s.nodes + s.helperNodes()
+}
+
+func (s *Searcher) helperNodes() uint64 {
+	var nodes uint64
+	for _, h := range s.helpers {
+		nodes += h.published.Load()
+	}
+	return nodes
+}
+
+// vote picks the result to play from those of all threads, the main
+// thread's first. Each thread votes for its best move with a weight that
+// grows with its depth and with how much better than the worst result it
+// scored, and the move with the most votes wins, with the PV of the
+// deepest thread that found it. A proven mate is played over the vote.
+func vote(results []Result) Result {
+	minScore := Infinity
+	for _, r := range results {
+		if len(r.PV) > 0 {
+			minScore = min(minScore, r.Score)
+		}
+	}
+
+	votes := make(map[chess.Move]int)
+	for _, r := range results {
+		if len(r.PV) > 0 {
+			votes[r.Move] += (r.Score - minScore + 14) * r.Depth
+		}
+	}
+
+	best := results[0]
+	for _, r := range results[1:] {
+		if len(r.PV) == 0 {
+			continue
+		}
+		switch {
+		case best.Score >= Mate-MaxPly || r.Score >= Mate-MaxPly:
+			// the shorter mate, or the mate over anything else
+			if r.Score > best.Score {
+				best = r
+			}
+		case votes[r.Move] > votes[best.Move],
+			r.Move == best.Move && r.Depth > best.Depth:
+			best = r
+		}
+	}
+	return best
+}
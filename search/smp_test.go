@@ -0,0 +1,127 @@
+package search
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/liam-hatcher/gohobbyengine/chess"
+)
+
+func TestSkipDepth(t *testing.T) {
+	for thread := 1; thread <= 40; thread++ {
+		if skipDepth(thread, 1) {
+			t.Errorf("helper %d: expected depth 1 to be searched", thread)
+		}
+
+		searched := 0
+		for depth := 2; depth <= 21; depth++ {
+			if !skipDepth(thread, depth) {
+				searched++
+			}
+		}
+		if searched < 8 || searched > 12 {
+			t.Errorf("helper %d: expected about half the depths searched, got %d of 20", thread, searched)
+		}
+	}
+
+	// neighbouring helpers search different depths
+	if skipDepth(1, 4) == skipDepth(2, 4) {
+		t.Error("expected helpers 1 and 2 to differ at depth 4")
+	}
+}
+
+func TestVote(t *testing.T) {
+	e2e4 := mustParseMove(t, "e2e4")
+	d2d4 := mustParseMove(t, "d2d4")
+	g1f3 := mustParseMove(t, "g1f3")
+	result := func(move chess.Move, score, depth int) Result {
+		return Result{Move: move, Score: score, Depth: depth, PV: []chess.Move{move}}
+	}
+
+	tests := []struct {
+		name     string
+		results  []Result
+		expected Result
+	}{
+		{"single thread", []Result{result(e2e4, 30, 8)}, result(e2e4, 30, 8)},
+		{"outvoted", []Result{result(e2e4, 30, 8), result(d2d4, 35, 8), result(d2d4, 32, 9)}, result(d2d4, 32, 9)},
+		{"deeper agreeing helper", []Result{result(e2e4, 30, 8), result(e2e4, 28, 10), result(g1f3, 20, 8)}, result(e2e4, 28, 10)},
+		{"mate", []Result{result(e2e4, 500, 12), result(g1f3, Mate-9, 8), result(d2d4, Mate-7, 7)}, result(d2d4, Mate-7, 7)},
+		{"helper without a move", []Result{result(e2e4, 30, 8), {}}, result(e2e4, 30, 8)},
+	}
+
+	for _, tt := range tests {
+		got := vote(tt.results)
+		if got.Move != tt.expected.Move || got.Depth != tt.expected.Depth {
+			t.Errorf("%s: expected %s at depth %d, got %s at depth %d", tt.name,
+				chess.ToUCINotation(tt.expected.Move), tt.expected.Depth, chess.ToUCINotation(got.Move), got.Depth)
+		}
+	}
+}
+
+func TestThreads(t *testing.T) {
+	s := NewSearcher()
+	s.Threads = 4
+	pos := mustParseFEN(t, BenchPositions[1])
+
+	single := NewSearcher().Search(pos, Limits{Depth: 6})
+	result := s.Search(pos, Limits{Depth: 6})
+	if len(result.PV) == 0 || !pos.IsPseudoLegal(result.Move) || !pos.IsLegal(result.Move) {
+		t.Fatalf("expected a legal move, got %s", chess.ToUCINotation(result.Move))
+	}
+	if result.Nodes <= single.Nodes {
+		t.Errorf("expected the nodes of all threads to be counted, got %d against %d for one", result.Nodes, single.Nodes)
+	}
+
+	// a second search counts only its own nodes, however many the helpers
+	// searched last time, and keeps to the node limit with them
+	var reported []uint64
+	s.Info = func(r Result) { reported = append(reported, r.Nodes) }
+	const limit = 50000
+	result = s.Search(pos, Limits{Nodes: limit})
+	for i := 1; i < len(reported); i++ {
+		if reported[i] < reported[i-1] {
+			t.Errorf("expected node counts never to decrease, got %v", reported)
+			break
+		}
+	}
+	if len(reported) > 0 && reported[0] >= single.Nodes {
+		t.Errorf("expected depth 1 to count only this search, got %d nodes", reported[0])
+	}
+	// the helpers may each run on for a check interval before they stop
+	if result.Nodes > limit+uint64(2*s.Threads*checkInterval) {
+		t.Errorf("expected about %d nodes, got %d", limit, result.Nodes)
+	}
+	s.Info = nil
+
+	// fewer threads than last time drops the extra helpers
+	s.Threads = 2
+	s.Search(pos, Limits{Depth: 3})
+	if len(s.helpers) != 1 {
+		t.Errorf("expected 1 helper, got %d", len(s.helpers))
+	}
+}
+
+func TestCancelStopsAllThreads(t *testing.T) {
+	s := NewSearcher()
+	s.Threads = 4
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	result := s.SearchContext(ctx, mustParseFEN(t, BenchPositions[1]), Limits{})
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("expected every thread to stop when cancelled, took %v", elapsed)
+	}
+	if result.Move == (chess.Move{}) {
+		t.Error("expected a move")
+	}
+
+	// with nothing left running, the node count no longer changes
+	nodes := s.totalNodes()
+	time.Sleep(20 * time.Millisecond)
+	if s.totalNodes() != nodes {
+		t.Error("expected the helpers to have stopped")
+	}
+}
@@ -0,0 +1,121 @@
+package search
+
+import (
+	"time"
+
+	"github.com/liam-hatcher/gohobbyengine/chess"
+)
+
+// the number of moves the remaining time is assumed to be for when the
+// time control doesn't say
+const defaultMovesToGo = 30
+
+// nodes searched between checks of the clock and the node limit, which is
+// also checked on the exact node it is reached
+const checkInterval = 1024
+
+// how long a search runs before it reports the root move it is on, see
+// Searcher.CurrMove. It is a variable so tests can shorten it.
+var currMoveDelay = 3 * time.Second
+
+// timeManager decides how long to search. The soft limit is the time the
+// search aims for: no new iteration starts after it, and it is scaled
+// after each iteration depending on how settled the result looks. The hard
+// limit stops the search even mid-iteration. A zero limit means no limit.
+type timeManager struct {
+	start      time.Time
+	soft, hard time.Duration
+
+	// how many iterations in a row have returned the same best move
+	stability int
+	lastMove  chess.Move
+	lastScore int
+
+	// ponderHit is closed when the opponent plays the move pondered on,
+	// and is nil when not pondering, see Limits.PonderHit
+	ponderHit <-chan struct{}
+}
+
+func newTimeManager(limits Limits, color int, start time.Time) *timeManager {
+	tm := &timeManager{start: start, ponderHit: limits.PonderHit}
+
+	if limits.MoveTime > 0 {
+		tm.soft = max(limits.MoveTime-limits.MoveOverhead, time.Millisecond)
+		tm.hard = tm.soft
+		return tm
+	}
+
+	remaining, increment := limits.WhiteTime, limits.WhiteIncrement
+	if color == chess.Black {
+		remaining, increment = limits.BlackTime, limits.BlackIncrement
+	}
+	if limits.WhiteTime <= 0 && limits.BlackTime <= 0 {
+		// no clock
+		return tm
+	}
+
+	movesToGo := limits.MovesToGo
+	if movesToGo <= 0 {
+		movesToGo = defaultMovesToGo
+	}
+
+	// keep the overhead for every move still to be played before the
+	// next time control, and never plan to use more than most of what is
+	// left on a single move
+	available := max(remaining-limits.MoveOverhead*time.Duration(min(movesToGo, 10)), time.Millisecond)
+	maximum := max(available*4/5, time.Millisecond)
+
+	tm.soft = max(min(available/time.Duration(movesToGo)+increment*3/4, maximum), time.Millisecond)
+	tm.hard = min(tm.soft*4, maximum)
+	return tm
+}
+
+func (tm *timeManager) elapsed() time.Duration {
+	return time.Since(tm.start)
+}
+
+// pondering reports whether the search is still on the opponent's time
+func (tm *timeManager) pondering() bool {
+	if tm.ponderHit == nil {
+		return false
+	}
+	select {
+	case <-tm.ponderHit:
+		tm.ponderHit = nil
+		return false
+	default:
+		return true
+	}
+}
+
+// hardLimitReached reports whether the search must stop now
+func (tm *timeManager) hardLimitReached() bool {
+	return tm.hard > 0 && !tm.pondering() && tm.elapsed() >= tm.hard
+}
+
+// iterationDone records the result of an iteration and reports whether
+// there is time for another. The soft limit is stretched while the best
+// move keeps changing or the score is dropping, and shrunk once the best
+// move has been stable for a few iterations.
+func (tm *timeManager) iterationDone(result Result) bool {
+	if result.Depth > 1 && result.Move == tm.lastMove {
+		tm.stability++
+	} else {
+		tm.stability = 0
+	}
+
+	scale := 1.4 - 0.1*float64(min(tm.stability, 6))
+	if result.Depth > 1 {
+		if drop := tm.lastScore - result.Score; drop > 0 {
+			scale *= 1 + float64(min(drop, 100))/100
+		}
+	}
+
+	tm.lastMove, tm.lastScore = result.Move, result.Score
+
+	if tm.soft == 0 || tm.pondering() {
+		return true
+	}
+	soft := min(time.Duration(float64(tm.soft)*scale), tm.hard)
+	return tm.elapsed() < soft
+}
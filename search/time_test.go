@@ -0,0 +1,131 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/liam-hatcher/gohobbyengine/chess"
+)
+
+func TestTimeManagerLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		limits     Limits
+		color      int
+		soft, hard time.Duration
+	}{
+		{"no clock", Limits{Depth: 5}, chess.White, 0, 0},
+		{"movetime", Limits{MoveTime: time.Second, MoveOverhead: 10 * time.Millisecond}, chess.White, 990 * time.Millisecond, 990 * time.Millisecond},
+		{"sudden death", Limits{WhiteTime: 30 * time.Second, BlackTime: time.Second}, chess.White, time.Second, 4 * time.Second},
+		{"black's clock", Limits{WhiteTime: time.Second, BlackTime: 30 * time.Second}, chess.Black, time.Second, 4 * time.Second},
+		{"increment", Limits{WhiteTime: 30 * time.Second, WhiteIncrement: time.Second}, chess.White, 1750 * time.Millisecond, 7 * time.Second},
+		{"moves to go", Limits{WhiteTime: 10 * time.Second, MovesToGo: 5}, chess.White, 2 * time.Second, 8 * time.Second},
+		{"last move before the control", Limits{WhiteTime: 10 * time.Second, MovesToGo: 1}, chess.White, 8 * time.Second, 8 * time.Second},
+		{"out of time", Limits{BlackTime: time.Second}, chess.White, time.Millisecond, time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		tm := newTimeManager(tt.limits, tt.color, time.Now())
+		if tm.soft != tt.soft || tm.hard != tt.hard {
+			t.Errorf("%s: expected soft %v and hard %v, got %v and %v", tt.name, tt.soft, tt.hard, tm.soft, tm.hard)
+		}
+	}
+}
+
+func TestTimeManagerKeepsOverhead(t *testing.T) {
+	// with almost no time left, the overhead still has to fit
+	tm := newTimeManager(Limits{WhiteTime: 200 * time.Millisecond, MoveOverhead: 10 * time.Millisecond}, chess.White, time.Now())
+	if tm.hard+10*time.Millisecond > 200*time.Millisecond {
+		t.Errorf("expected the hard limit to leave the overhead, got %v", tm.hard)
+	}
+}
+
+func TestTimeManagerStability(t *testing.T) {
+	e2e4 := chess.Move{From: 12, To: 28}
+	d2d4 := chess.Move{From: 11, To: 27}
+
+	// pretend 1s of a 1s soft limit has passed: a stable best move stops,
+	// one that keeps changing, or a falling score, gets more time
+	start := time.Now().Add(-time.Second)
+	newTM := func() *timeManager {
+		tm := newTimeManager(Limits{MoveTime: time.Hour}, chess.White, start)
+		tm.soft = time.Second
+		return tm
+	}
+
+	tm := newTM()
+	for depth := 1; depth <= 8; depth++ {
+		tm.iterationDone(Result{Move: e2e4, Depth: depth, Score: 20})
+	}
+	if tm.iterationDone(Result{Move: e2e4, Depth: 9, Score: 20}) {
+		t.Error("expected a stable best move to use less than the soft limit")
+	}
+
+	tm = newTM()
+	for depth := 1; depth <= 8; depth++ {
+		move := e2e4
+		if depth%2 == 0 {
+			move = d2d4
+		}
+		if !tm.iterationDone(Result{Move: move, Depth: depth, Score: 20}) {
+			t.Errorf("depth %d: expected a changing best move to get more time", depth)
+		}
+	}
+
+	tm = newTM()
+	for depth := 1; depth <= 8; depth++ {
+		tm.iterationDone(Result{Move: e2e4, Depth: depth, Score: 20})
+	}
+	if !tm.iterationDone(Result{Move: e2e4, Depth: 9, Score: -80}) {
+		t.Error("expected a falling score to get more time")
+	}
+}
+
+func TestSearchStopsAtLimits(t *testing.T) {
+	pos := mustParseFEN(t, BenchPositions[1])
+
+	result := NewSearcher().Search(pos, Limits{Nodes: 20000})
+	if result.Move == (chess.Move{}) || result.Nodes > 20000 {
+		t.Errorf("expected a move within 20000 nodes, got %s after %d", chess.ToUCINotation(result.Move), result.Nodes)
+	}
+
+	start := time.Now()
+	result = NewSearcher().Search(pos, Limits{MoveTime: 100 * time.Millisecond})
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond || result.Move == (chess.Move{}) {
+		t.Errorf("expected a move within 100ms, took %v", elapsed)
+	}
+
+	// mate in 2 is found at depth 1, so a mate limit stops straight away
+	result = NewSearcher().Search(mustParseFEN(t, "r2qkb1r/pp2nppp/3p4/2pNN1B1/2BnP3/3P4/PPP2PPP/R2bK2R w KQkq - 1 10"), Limits{Mate: 2})
+	if result.Depth != 1 || result.Score != Mate-3 {
+		t.Errorf("expected to stop at depth 1 with the mate, got depth %d score %d", result.Depth, result.Score)
+	}
+}
+
+func TestSearchIgnoresTimeWhilePondering(t *testing.T) {
+	pos := mustParseFEN(t, BenchPositions[1])
+	ponderHit := make(chan struct{})
+	limits := Limits{MoveTime: 50 * time.Millisecond, PonderHit: ponderHit}
+
+	done := make(chan Result)
+	go func() {
+		done <- NewSearcher().Search(pos, limits)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("expected the search to keep going past the move time while pondering")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// the time already spent counts once the opponent plays the move
+	close(ponderHit)
+	select {
+	case result := <-done:
+		if result.Move == (chess.Move{}) {
+			t.Error("expected a move after the ponder hit")
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("expected the search to stop soon after the ponder hit")
+	}
+}
@@ -0,0 +1,246 @@
+package search
+
+import (
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/liam-hatcher/gohobbyengine/chess"
+)
+
+// Bound says how a stored score relates to the true score of a position
+type Bound uint8
+
+const (
+	BoundNone Bound = iota
+	// the true score is at most the stored score (the search failed low)
+	BoundUpper
+	// the true score is at least the stored score (the search failed high)
+	BoundLower
+	BoundExact
+)
+
+// TTEntry is one stored search result. Scores are stored relative to the
+// position rather than the root, see scoreToTT.
+type TTEntry struct {
+	move  uint16
+	score int16
+	depth int8
+	bound Bound
+	age   uint8
+}
+
+func (e *TTEntry) Move() chess.Move { return unpackMove(e.move) }
+func (e *TTEntry) Depth() int       { return int(e.depth) }
+func (e *TTEntry) Bound() Bound     { return e.bound }
+
+// Score returns the stored score adjusted to be relative to the root of a
+// search that found the entry ply plies deep
+func (e *TTEntry) Score(ply int) int { return scoreFromTT(int(e.score), ply) }
+
+// pack packs the entry into a single word for a ttSlot
+func (e *TTEntry) pack() uint64 {
+	return uint64(e.move) | uint64(uint16(e.score))<<16 | uint64(uint8(e.depth))<<32 |
+		uint64(e.bound)<<40 | uint64(e.age)<<48
+}
+
+func unpackEntry(data uint64) TTEntry {
+	return TTEntry{
+		move:  uint16(data),
+		score: int16(data >> 16),
+		depth: int8(data >> 32),
+		bound: Bound(data >> 40),
+		age:   uint8(data >> 48),
+	}
+}
+
+// ttSlot holds an entry packed into one word together with its key XORed
+// with that word. Several search threads read and write the table without
+// locking, and a slot they write at the same time can end up with the key
+// of one entry and the data of another. Such a slot doesn't match either
+// key, so the torn entry is never used.
+type ttSlot struct {
+	key  atomic.Uint64
+	data atomic.Uint64
+}
+
+func (s *ttSlot) load() (uint64, TTEntry) {
+	data := s.data.Load()
+	return s.key.Load() ^ data, unpackEntry(data)
+}
+
+func (s *ttSlot) store(key uint64, e TTEntry) {
+	data := e.pack()
+	s.key.Store(key ^ data)
+	s.data.Store(data)
+}
+
+// the entries of a bucket share a cache line
+const bucketSize = 4
+
+type ttBucket [bucketSize]ttSlot
+
+const (
+	DefaultHashMB = 16
+	MinHashMB     = 1
+	MaxHashMB     = 4096
+)
+
+// TranspositionTable remembers the results of searches by Zobrist key, so
+// a position reached again, through a transposition or in a later
+// iteration, doesn't have to be searched again. Its size is a power of two
+// number of buckets so the key can be masked to find a bucket.
+//
+// Probe and Store are safe to call from several goroutines at once, the
+// other methods must only be called while no search is running.
+type TranspositionTable struct {
+	buckets []ttBucket
+	mask    uint64
+	age     uint8
+}
+
+func NewTranspositionTable(megabytes int) *TranspositionTable {
+	t := &TranspositionTable{}
+	t.Resize(megabytes)
+	return t
+}
+
+// Resize allocates a new, empty table of the largest power of two number
+// of buckets that fits in the given number of megabytes
+func (t *TranspositionTable) Resize(megabytes int) {
+	megabytes = min(max(megabytes, MinHashMB), MaxHashMB)
+
+	count := uint64(megabytes) << 20 / uint64(unsafe.Sizeof(ttBucket{}))
+	for count&(count-1) != 0 {
+		count &= count - 1
+	}
+
+	t.buckets = make([]ttBucket, count)
+	t.mask = count - 1
+	t.age = 0
+}
+
+// Clear empties the table, as between games
+func (t *TranspositionTable) Clear() {
+	clear(t.buckets)
+	t.age = 0
+}
+
+// NewSearch ages the table, so entries left by earlier searches are
+// replaced before those of the current one
+func (t *TranspositionTable) NewSearch() {
+	t.age++
+}
+
+// Probe looks up key and returns its entry if there is one
+func (t *TranspositionTable) Probe(key uint64) (TTEntry, bool) {
+	bucket := &t.buckets[key&t.mask]
+	for i := range bucket {
+		if k, entry := bucket[i].load(); k == key && entry.bound != BoundNone {
+			return entry, true
+		}
+	}
+	return TTEntry{}, false
+}
+
+// Store saves a search result for key. An existing entry for the same key
+// is overwritten, keeping its move if the new result has none. Otherwise
+// the entry replaced is the one with the lowest depth, counting entries
+// from older searches as shallower the older they are.
+func (t *TranspositionTable) Store(key uint64, move chess.Move, score, depth, ply int, bound Bound) {
+	bucket := &t.buckets[key&t.mask]
+
+	replace := 0
+	var replaced TTEntry
+	for i := range bucket {
+		k, entry := bucket[i].load()
+		if k == key || entry.bound == BoundNone {
+			replace, replaced = i, entry
+			if k != key {
+				replaced.move = 0
+			}
+			break
+		}
+		if i == 0 || t.worth(entry) < t.worth(replaced) {
+			replace, replaced = i, entry
+			replaced.move = 0
+		}
+	}
+
+	packed := packMove(move)
+	if move == (chess.Move{}) {
+		packed = replaced.move
+	}
+
+	bucket[replace].store(key, TTEntry{
+		move:  packed,
+		score: int16(scoreToTT(score, ply)),
+		depth: int8(depth),
+		bound: bound,
+		age:   t.age,
+	})
+}
+
+// worth ranks entries for replacement, the lowest is replaced first
+func (t *TranspositionTable) worth(e TTEntry) int {
+	return int(e.depth) - 8*int(t.age-e.age)
+}
+
+// Hashfull returns how full the table is in permille, for UCI's hashfull,
+// by counting the entries of the current search in the first buckets
+func (t *TranspositionTable) Hashfull() int {
+	sample := min(len(t.buckets), 1000/bucketSize)
+
+	used := 0
+	for i := 0; i < sample; i++ {
+		for j := range t.buckets[i] {
+			if _, entry := t.buckets[i][j].load(); entry.bound != BoundNone && entry.age == t.age {
+				used++
+			}
+		}
+	}
+	return used * 1000 / (sample * bucketSize)
+}
+
+// Mate scores count plies from the root, but an entry can be found at any
+// ply, so they are stored as plies from the position itself
+func scoreToTT(score, ply int) int {
+	if score >= Mate-MaxPly {
+		return score + ply
+	}
+	if score <= -Mate+MaxPly {
+		return score - ply
+	}
+	return score
+}
+
+func scoreFromTT(score, ply int) int {
+	if score >= Mate-MaxPly {
+		return score - ply
+	}
+	if score <= -Mate+MaxPly {
+		return score + ply
+	}
+	return score
+}
+
+// moves are packed into 16 bits for the table: 6 bits each for the from
+// and to squares and the promotion piece in the top 4 bits
+var promotionCodes = [...]byte{0, 'n', 'b', 'r', 'q'}
+
+func packMove(m chess.Move) uint16 {
+	packed := uint16(m.From) | uint16(m.To)<<6
+	for code, promo := range promotionCodes {
+		if m.Promo != 0 && m.Promo == promo {
+			packed |= uint16(code) << 12
+		}
+	}
+	return packed
+}
+
+func unpackMove(packed uint16) chess.Move {
+	return chess.Move{
+		From:  int(packed & 63),
+		To:    int(packed >> 6 & 63),
+		Promo: promotionCodes[packed>>12],
+	}
+}
@@ -0,0 +1,128 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/liam-hatcher/gohobbyengine/chess"
+)
+
+func TestPackMove(t *testing.T) {
+	for _, uci := range []string{"a1a1", "e2e4", "h7h8q", "b2a1n", "g7g8r", "c7c8b"} {
+		move, _ := chess.ParseUCIMove(uci)
+		if got := unpackMove(packMove(move)); got != move {
+			t.Errorf("expected %s to survive packing, got %s", uci, chess.ToUCINotation(got))
+		}
+	}
+}
+
+func TestTranspositionTableSize(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	if n := len(tt.buckets); n&(n-1) != 0 || n*bucketSize*16 > 1<<20 {
+		t.Errorf("expected a power of two number of buckets within 1MB, got %d", n)
+	}
+}
+
+func TestTranspositionTableStoreAndProbe(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	move, _ := chess.ParseUCIMove("e7e8q")
+
+	tt.Store(0x1234, move, 42, 5, 3, BoundLower)
+	entry, ok := tt.Probe(0x1234)
+	if !ok {
+		t.Fatal("expected to find the stored entry")
+	}
+	if entry.Move() != move || entry.Score(3) != 42 || entry.Depth() != 5 || entry.Bound() != BoundLower {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+
+	if _, ok := tt.Probe(0x1234 + uint64(len(tt.buckets))); ok {
+		t.Error("expected a different key in the same bucket not to match")
+	}
+
+	// a result without a move keeps the move already stored
+	tt.Store(0x1234, chess.Move{}, 10, 6, 3, BoundUpper)
+	if entry, _ := tt.Probe(0x1234); entry.Move() != move || entry.Depth() != 6 {
+		t.Errorf("expected the move to be kept, got %+v", entry)
+	}
+
+	tt.Clear()
+	if _, ok := tt.Probe(0x1234); ok {
+		t.Error("expected the table to be empty after Clear")
+	}
+}
+
+func TestTranspositionTableMateScores(t *testing.T) {
+	tt := NewTranspositionTable(1)
+
+	// mate in 3 plies from a position found 4 plies into the search...
+	tt.Store(1, chess.Move{}, Mate-7, 5, 4, BoundExact)
+	// ...is mate in 3 plies from the same position found at ply 2
+	if entry, _ := tt.Probe(1); entry.Score(2) != Mate-5 {
+		t.Errorf("expected %d, got %d", Mate-5, entry.Score(2))
+	}
+
+	tt.Store(2, chess.Move{}, -Mate+6, 5, 4, BoundExact)
+	if entry, _ := tt.Probe(2); entry.Score(1) != -Mate+3 {
+		t.Errorf("expected %d, got %d", -Mate+3, entry.Score(1))
+	}
+}
+
+func TestTranspositionTableReplacement(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	stride := uint64(len(tt.buckets))
+
+	// fill a bucket, then one more key has to replace the shallowest entry
+	for i, depth := range []int{4, 2, 6, 3} {
+		tt.Store(uint64(i)*stride, chess.Move{}, 0, depth, 0, BoundExact)
+	}
+	tt.Store(4*stride, chess.Move{}, 0, 1, 0, BoundExact)
+	if _, ok := tt.Probe(1 * stride); ok {
+		t.Error("expected the depth 2 entry to be replaced")
+	}
+
+	// entries from an old search go before shallower ones from this one
+	tt.NewSearch()
+	tt.Store(5*stride, chess.Move{}, 0, 1, 0, BoundExact)
+	tt.Store(6*stride, chess.Move{}, 0, 1, 0, BoundExact)
+	if _, ok := tt.Probe(5 * stride); !ok {
+		t.Error("expected the entry from this search to be kept")
+	}
+	if _, ok := tt.Probe(2 * stride); !ok {
+		t.Error("expected the deepest old entry to be kept")
+	}
+	if _, ok := tt.Probe(3 * stride); ok {
+		t.Error("expected the old depth 3 entry to be replaced")
+	}
+}
+
+func TestHashfull(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	if tt.Hashfull() != 0 {
+		t.Errorf("expected an empty table, got %d", tt.Hashfull())
+	}
+
+	for i := 0; i < 1000/bucketSize; i++ {
+		for j := 0; j < bucketSize/2; j++ {
+			tt.Store(uint64(i+j*len(tt.buckets)), chess.Move{}, 0, 1, 0, BoundExact)
+		}
+	}
+	if tt.Hashfull() != 500 {
+		t.Errorf("expected the table to be half full, got %d", tt.Hashfull())
+	}
+
+	tt.NewSearch()
+	if tt.Hashfull() != 0 {
+		t.Errorf("expected old entries not to count, got %d", tt.Hashfull())
+	}
+}
+
+func TestSearchReusesTranspositionTable(t *testing.T) {
+	s := NewSearcher()
+	pos := mustParseFEN(t, "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+
+	first := s.Search(pos, Limits{Depth: 3})
+	second := s.Search(pos, Limits{Depth: 3})
+	if second.Nodes >= first.Nodes {
+		t.Errorf("expected the second search to use the table, searched %d then %d nodes", first.Nodes, second.Nodes)
+	}
+}
@@ -0,0 +1,100 @@
+package uci
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/liam-hatcher/gohobbyengine/chess"
+	"github.com/liam-hatcher/gohobbyengine/search"
+)
+
+// goCommand holds the parameters of a "go" command
+type goCommand struct {
+	limits search.Limits
+
+	// infinite searches until "stop", and holds back bestmove until then
+	// even if the search finishes earlier
+	infinite bool
+
+	// ponder searches on the opponent's time, with the clock given for
+	// after the move pondered on. The search runs until "ponderhit" or
+	// "stop", and bestmove waits for one of them.
+	ponder bool
+}
+
+// parseGo reads the fields of a "go" command, e.g.
+// "go wtime 300000 btime 300000 winc 2000 binc 2000". Times are given in
+// milliseconds. A bare "go" searches to defaultSearchDepth, as does one
+// with searchmoves but no limits.
+func parseGo(fields []string) (goCommand, error) {
+	var cmd goCommand
+	limits := &cmd.limits
+	limited := false
+
+	for i := 1; i < len(fields); i++ {
+		name := fields[i]
+		if name == "infinite" {
+			cmd.infinite = true
+			limited = true
+			continue
+		}
+		if name == "ponder" {
+			cmd.ponder = true
+			continue
+		}
+		if name == "searchmoves" {
+			// the moves run until the next parameter or the end
+			for i+1 < len(fields) {
+				move, err := chess.ParseUCIMove(fields[i+1])
+				if err != nil {
+					break
+				}
+				limits.SearchMoves = append(limits.SearchMoves, move)
+				i++
+			}
+			continue
+		}
+
+		if i+1 >= len(fields) {
+			return cmd, fmt.Errorf("missing value for %s", name)
+		}
+		i++
+		value, err := strconv.ParseInt(fields[i], 10, 64)
+		if err != nil {
+			return cmd, fmt.Errorf("invalid value %q for %s", fields[i], name)
+		}
+		// some GUIs send negative times once the clock has run out
+		value = max(value, 0)
+		ms := time.Duration(value) * time.Millisecond
+
+		switch name {
+		case "wtime":
+			limits.WhiteTime = ms
+		case "btime":
+			limits.BlackTime = ms
+		case "winc":
+			limits.WhiteIncrement = ms
+		case "binc":
+			limits.BlackIncrement = ms
+		case "movestogo":
+			limits.MovesToGo = int(value)
+		case "movetime":
+			limits.MoveTime = ms
+		case "depth":
+			limits.Depth = int(value)
+		case "nodes":
+			limits.Nodes = uint64(value)
+		case "mate":
+			limits.Mate = int(value)
+		default:
+			return cmd, fmt.Errorf("unknown go parameter %s", name)
+		}
+		limited = true
+	}
+
+	if !limited {
+		limits.Depth = defaultSearchDepth
+	}
+	return cmd, nil
+}
@@ -0,0 +1,55 @@
+package uci
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liam-hatcher/gohobbyengine/chess"
+	"github.com/liam-hatcher/gohobbyengine/search"
+)
+
+func TestParseGo(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected goCommand
+	}{
+		{"go", goCommand{limits: search.Limits{Depth: defaultSearchDepth}}},
+		{"go infinite", goCommand{infinite: true}},
+		{"go depth 8", goCommand{limits: search.Limits{Depth: 8}}},
+		{"go nodes 100000 mate 3", goCommand{limits: search.Limits{Nodes: 100000, Mate: 3}}},
+		{"go movetime 2500", goCommand{limits: search.Limits{MoveTime: 2500 * time.Millisecond}}},
+		{"go wtime 60000 btime 55000 winc 1000 binc 500 movestogo 20", goCommand{limits: search.Limits{
+			WhiteTime: time.Minute, BlackTime: 55 * time.Second,
+			WhiteIncrement: time.Second, BlackIncrement: 500 * time.Millisecond,
+			MovesToGo: 20,
+		}}},
+		{"go ponder wtime 1000 btime 2000", goCommand{limits: search.Limits{
+			WhiteTime: time.Second, BlackTime: 2 * time.Second,
+		}, ponder: true}},
+		{"go searchmoves e2e4 g1f3 infinite", goCommand{limits: search.Limits{
+			SearchMoves: []chess.Move{{From: 12, To: 28}, {From: 6, To: 21}},
+		}, infinite: true}},
+		{"go depth 3 searchmoves a7a8q", goCommand{limits: search.Limits{
+			Depth: 3, SearchMoves: []chess.Move{{From: 48, To: 56, Promo: 'q'}},
+		}}},
+		{"go wtime -150 btime 1000", goCommand{limits: search.Limits{BlackTime: time.Second}}},
+	}
+
+	for _, tt := range tests {
+		cmd, err := parseGo(strings.Fields(tt.command))
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.command, err)
+		}
+		if !reflect.DeepEqual(cmd, tt.expected) {
+			t.Errorf("%s: expected %+v, got %+v", tt.command, tt.expected, cmd)
+		}
+	}
+
+	for _, command := range []string{"go depth", "go depth x", "go speed 5"} {
+		if _, err := parseGo(strings.Fields(command)); err == nil {
+			t.Errorf("%s: expected an error", command)
+		}
+	}
+}
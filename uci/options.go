@@ -0,0 +1,206 @@
+package uci
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/liam-hatcher/gohobbyengine/search"
+)
+
+// option is an engine setting a GUI can change with "setoption". Options
+// are advertised in response to "uci" in the order they are listed.
+type option struct {
+	name     string
+	kind     string // "spin", "check", "combo", "button" or "string"
+	def      string
+	min, max int
+
+	apply func(e *Engine, value string) error
+}
+
+// defaultMoveOverhead is the time kept back on each move for the delay
+// between the engine and the GUI's clock
+const defaultMoveOverhead = 10 * time.Millisecond
+
+// maxMultiPV is more than the number of legal moves in any position
+const maxMultiPV = 256
+
+var options = []option{
+	{
+		name: "Hash", kind: "spin",
+		def: strconv.Itoa(search.DefaultHashMB), min: search.MinHashMB, max: search.MaxHashMB,
+		apply: func(e *Engine, value string) error {
+			megabytes, err := spinValue(value, search.MinHashMB, search.MaxHashMB)
+			if err != nil {
+				return err
+			}
+			e.searcher.TT.Resize(megabytes)
+			return nil
+		},
+	},
+	{
+		name: "Threads", kind: "spin", def: "1", min: 1, max: search.MaxThreads,
+		apply: func(e *Engine, value string) error {
+			threads, err := spinValue(value, 1, search.MaxThreads)
+			if err != nil {
+				return err
+			}
+			e.searcher.Threads = threads
+			return nil
+		},
+	},
+	{
+		name: "Move Overhead", kind: "spin",
+		def: strconv.Itoa(int(defaultMoveOverhead.Milliseconds())), min: 0, max: 5000,
+		apply: func(e *Engine, value string) error {
+			ms, err := spinValue(value, 0, 5000)
+			if err != nil {
+				return err
+			}
+			e.moveOverhead = time.Duration(ms) * time.Millisecond
+			return nil
+		},
+	},
+	{
+		name: "MultiPV", kind: "spin", def: "1", min: 1, max: maxMultiPV,
+		apply: func(e *Engine, value string) error {
+			lines, err := spinValue(value, 1, maxMultiPV)
+			if err != nil {
+				return err
+			}
+			e.multiPV = lines
+			return nil
+		},
+	},
+	{
+		name: "Skill Level", kind: "spin",
+		def: strconv.Itoa(search.MaxSkillLevel), min: 0, max: search.MaxSkillLevel,
+		apply: func(e *Engine, value string) error {
+			level, err := spinValue(value, 0, search.MaxSkillLevel)
+			if err != nil {
+				return err
+			}
+			e.skillLevel = level
+			return nil
+		},
+	},
+	{
+		// takes precedence over Skill Level when set
+		name: "UCI_LimitStrength", kind: "check", def: "false",
+		apply: func(e *Engine, value string) error {
+			limit, err := checkValue(value)
+			if err != nil {
+				return err
+			}
+			e.limitStrength = limit
+			return nil
+		},
+	},
+	{
+		name: "UCI_Elo", kind: "spin",
+		def: strconv.Itoa(search.MinSkillElo), min: search.MinSkillElo, max: search.MaxSkillElo,
+		apply: func(e *Engine, value string) error {
+			elo, err := spinValue(value, search.MinSkillElo, search.MaxSkillElo)
+			if err != nil {
+				return err
+			}
+			e.elo = elo
+			return nil
+		},
+	},
+	// the forward pruning switches, so each can be measured in self-play
+	switchOption("ReverseFutility", func(o *search.Options) *bool { return &o.ReverseFutility }),
+	switchOption("Futility", func(o *search.Options) *bool { return &o.Futility }),
+	switchOption("Razoring", func(o *search.Options) *bool { return &o.Razoring }),
+	switchOption("ProbCut", func(o *search.Options) *bool { return &o.ProbCut }),
+	{
+		// the GUI decides whether to ponder, the engine only needs to
+		// accept "go ponder" and "ponderhit"
+		name: "Ponder", kind: "check", def: "false",
+		apply: func(e *Engine, value string) error {
+			_, err := checkValue(value)
+			return err
+		},
+	},
+}
+
+// switchOption returns a check option setting the search option field
+// returns, with the default from search.DefaultOptions
+func switchOption(name string, field func(*search.Options) *bool) option {
+	return option{
+		name: name, kind: "check", def: strconv.FormatBool(*field(&search.DefaultOptions)),
+		apply: func(e *Engine, value string) error {
+			on, err := checkValue(value)
+			if err != nil {
+				return err
+			}
+			*field(&e.searcher.Options) = on
+			return nil
+		},
+	}
+}
+
+// declaration returns the "option" line advertising o to the GUI
+func (o option) declaration() string {
+	line := fmt.Sprintf("option name %s type %s", o.name, o.kind)
+	if o.kind != "button" {
+		line += " default " + o.def
+	}
+	if o.kind == "spin" {
+		line += fmt.Sprintf(" min %d max %d", o.min, o.max)
+	}
+	return line
+}
+
+func spinValue(value string, min, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+	if n < min || n > max {
+		return 0, fmt.Errorf("%d is outside the range %d to %d", n, min, max)
+	}
+	return n, nil
+}
+
+func checkValue(value string) (bool, error) {
+	switch value {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid check value %q", value)
+}
+
+// parseSetOption splits the fields of "setoption name <id> [value <x>]"
+// into the name and value, either of which can contain spaces
+func parseSetOption(fields []string) (name, value string) {
+	var nameFields, valueFields []string
+	target := &nameFields
+	for _, f := range fields[1:] {
+		switch {
+		case f == "name" && len(nameFields) == 0:
+			target = &nameFields
+		case f == "value" && target == &nameFields:
+			target = &valueFields
+		default:
+			*target = append(*target, f)
+		}
+	}
+	return strings.Join(nameFields, " "), strings.Join(valueFields, " ")
+}
+
+// setOption applies a "setoption" command. Option names are case
+// insensitive.
+func (e *Engine) setOption(fields []string) error {
+	name, value := parseSetOption(fields)
+	for _, o := range options {
+		if strings.EqualFold(o.name, name) {
+			return o.apply(e, value)
+		}
+	}
+	return fmt.Errorf("unknown option %q", name)
+}
@@ -0,0 +1,59 @@
+package uci
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liam-hatcher/gohobbyengine/search"
+)
+
+func TestPruningOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		field func(*search.Options) bool
+	}{
+		{"ReverseFutility", func(o *search.Options) bool { return o.ReverseFutility }},
+		{"Futility", func(o *search.Options) bool { return o.Futility }},
+		{"Razoring", func(o *search.Options) bool { return o.Razoring }},
+		{"ProbCut", func(o *search.Options) bool { return o.ProbCut }},
+	}
+
+	for _, tt := range tests {
+		e := NewEngine()
+		if !tt.field(&e.searcher.Options) {
+			t.Errorf("%s: expected it to be on by default", tt.name)
+		}
+
+		if err := e.setOption(strings.Fields("setoption name " + tt.name + " value false")); err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.name, err)
+		}
+		if tt.field(&e.searcher.Options) {
+			t.Errorf("%s: expected it to be turned off", tt.name)
+		}
+		// only the one switch changes
+		if e.searcher.Options == search.DefaultOptions {
+			t.Errorf("%s: expected the options to differ from the defaults", tt.name)
+		}
+
+		if err := e.setOption(strings.Fields("setoption name " + tt.name + " value true")); err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.name, err)
+		}
+		if e.searcher.Options != search.DefaultOptions {
+			t.Errorf("%s: expected it to be back on, got %+v", tt.name, e.searcher.Options)
+		}
+
+		if err := e.setOption(strings.Fields("setoption name " + tt.name + " value off")); err == nil {
+			t.Errorf("%s: expected an error for an invalid check value", tt.name)
+		}
+	}
+}
+
+func TestPruningOptionsAreAdvertised(t *testing.T) {
+	s := newSession(t)
+	s.send("uci")
+	for _, name := range []string{"ReverseFutility", "Futility", "Razoring", "ProbCut"} {
+		s.expect("option name "+name+" type check default true$", time.Second)
+	}
+	s.expect("uciok", time.Second)
+}
@@ -2,13 +2,16 @@ package uci
 
 import (
 	"bufio"
+	"context"
 	"fmt"
-	"math/rand"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/liam-hatcher/gohobbyengine/chess"
+	"github.com/liam-hatcher/gohobbyengine/search"
 )
 
 const (
@@ -17,10 +20,34 @@ const (
 	NotInitialized
 )
 
+// the depth searched by a "go" without any limits
+const defaultSearchDepth = 5
+
 type Engine struct {
 	MoveHistory   []string
 	EngineColor   int
 	FirstMoveDone bool
+
+	searcher     *search.Searcher
+	moveOverhead time.Duration
+	multiPV      int
+
+	// the strength settings, see skill
+	skillLevel    int
+	limitStrength bool
+	elo           int
+
+	// out writes to the GUI, it is set up by Loop, see send
+	out *lineWriter
+
+	// cancel stops the running search, and searchDone is closed once it
+	// has sent its bestmove. Both are nil when no search is running.
+	cancel     context.CancelFunc
+	searchDone chan struct{}
+
+	// ponderHit is closed on "ponderhit" to turn a "go ponder" search
+	// into a normal one, and is nil when not pondering
+	ponderHit chan struct{}
 }
 
 func NewEngine() *Engine {
@@ -28,6 +55,11 @@ func NewEngine() *Engine {
 		MoveHistory:   []string{},
 		EngineColor:   NotInitialized,
 		FirstMoveDone: false,
+		searcher:      search.NewSearcher(),
+		moveOverhead:  defaultMoveOverhead,
+		multiPV:       1,
+		skillLevel:    search.MaxSkillLevel,
+		elo:           search.MinSkillElo,
 	}
 }
 
@@ -49,40 +81,196 @@ func LogCommand(prefix, message string) {
 	fmt.Fprintf(os.Stderr, "[%s] %s: %s\n", timestamp, prefix, message)
 }
 
-func (e *Engine) HandleGo(p *chess.Position) string {
-	if e.EngineColor == White {
-		// play some random pawn moves for now
-		moves := p.GenerateWhitePawnMoves()
-		uciMoves := make([]string, len(moves))
-		for i, m := range moves {
-			uciMoves[i] = chess.ToUCINotation(m)
+// HandleGo searches the position and returns the best move in UCI
+// notation, or the null move "0000" if there are no legal moves
+func (e *Engine) HandleGo(p *chess.Position, limits search.Limits) string {
+	return bestMove(e.search(context.Background(), p, limits))
+}
+
+func (e *Engine) search(ctx context.Context, p *chess.Position, limits search.Limits) search.Result {
+	limits.MoveOverhead = e.moveOverhead
+	limits.MultiPV = e.multiPV
+	e.searcher.Info = e.sendInfo
+	e.searcher.CurrMove = e.sendCurrMove
+	e.searcher.Skill = e.skill()
+	return e.searcher.SearchContext(ctx, p, limits)
+}
+
+// skill returns how much to weaken the search: UCI_Elo when
+// UCI_LimitStrength is on, otherwise Skill Level, or nil for full strength
+func (e *Engine) skill() *search.Skill {
+	if e.limitStrength {
+		return search.SkillFromElo(e.elo)
+	}
+	return search.NewSkill(float64(e.skillLevel))
+}
+
+// bestMove returns the move to play from result in UCI notation, or the
+// null move "0000" if there are no legal moves
+func bestMove(result search.Result) string {
+	if len(result.PV) == 0 {
+		return "0000"
+	}
+	return chess.ToUCINotation(result.Move)
+}
+
+// bestMoveCommand returns the "bestmove" line for result, with the reply
+// the engine expects as its ponder move when the PV has one
+func bestMoveCommand(result search.Result) string {
+	line := "bestmove " + bestMove(result)
+	if len(result.PV) > 1 {
+		line += " ponder " + chess.ToUCINotation(result.PV[1])
+	}
+	return line
+}
+
+// the depth searched by "bench" unless one is given
+const defaultBenchDepth = 5
+
+// bench searches a fixed set of positions, see search.Bench, and reports
+// the node count, e.g. "bench" or "bench 6" to search to depth 6
+func (e *Engine) bench(fields []string) {
+	depth := defaultBenchDepth
+	if len(fields) > 1 {
+		d, err := strconv.Atoi(fields[1])
+		if err != nil || d <= 0 {
+			e.diagnostic(fmt.Sprintf("invalid bench depth %q", fields[1]))
+			return
 		}
+		depth = d
+	}
+
+	i := 0
+	nodes, elapsed := search.Bench(depth, func(r search.BenchResult) {
+		i++
+		e.send(fmt.Sprintf("info string position %d/%d nodes %d bestmove %s fen %s",
+			i, len(search.BenchPositions), r.Result.Nodes, chess.ToUCINotation(r.Result.Move), r.FEN))
+	})
+
+	nps := uint64(0)
+	if elapsed > 0 {
+		nps = uint64(float64(nodes) / elapsed.Seconds())
+	}
+	e.send(fmt.Sprintf("info string bench depth %d nodes %d time %d nps %d",
+		depth, nodes, elapsed.Milliseconds(), nps))
+}
+
+// send writes a line to the GUI. It is safe to call from the search
+// goroutine, and does nothing outside Loop.
+func (e *Engine) send(line string) {
+	if e.out != nil {
+		e.out.send(line)
+	}
+}
 
-		LogCommand("DEBUG", fmt.Sprintf("%+v\n", uciMoves))
+// diagnostic reports a problem, such as a command that couldn't be
+// understood, on stderr and to the GUI as an "info string"
+func (e *Engine) diagnostic(message string) {
+	LogCommand("ERROR", message)
+	e.send("info string " + message)
+}
 
-		r := rand.New(rand.NewSource(time.Now().UnixNano()))
-		randomIndex := r.Intn(len(uciMoves))
+// sendInfo reports a line of a completed iteration of the search to the
+// GUI
+func (e *Engine) sendInfo(result search.Result) {
+	nps := uint64(0)
+	if result.Time > 0 {
+		nps = uint64(float64(result.Nodes) / result.Time.Seconds())
+	}
+	e.send(fmt.Sprintf("info depth %d seldepth %d multipv %d score %s nodes %d nps %d time %d hashfull %d pv %s",
+		result.Depth, result.SelDepth, result.MultiPV, uciScore(result.Score), result.Nodes, nps,
+		result.Time.Milliseconds(), e.searcher.TT.Hashfull(), strings.Join(uciMoves(result.PV), " ")))
+}
 
-		randMove := uciMoves[randomIndex]
-		p.ApplyMove(uciMoves[randomIndex])
+// sendCurrMove reports the root move the search is on, see
+// search.Searcher.CurrMove
+func (e *Engine) sendCurrMove(depth int, move chess.Move, number int) {
+	e.send(fmt.Sprintf("info depth %d currmove %s currmovenumber %d", depth, chess.ToUCINotation(move), number))
+}
 
-		LogCommand("BLACK Pawns: ", fmt.Sprintf("%d", p.BlackPawns))
-		return randMove
-	} else {
-		// dummy move for black right now=
-		return "h7h6"
+// uciScore formats a score for "info", as "cp" in centipawns or as "mate"
+// in moves, negative when the engine is getting mated
+func uciScore(score int) string {
+	if moves, mate := search.MateIn(score); mate {
+		return fmt.Sprintf("mate %d", moves)
 	}
+	return fmt.Sprintf("cp %d", score)
 }
 
-func (e *Engine) Run(p *chess.Position) {
-	scanner := bufio.NewScanner(os.Stdin)
-	writer := bufio.NewWriter(os.Stdout)
+func uciMoves(moves []chess.Move) []string {
+	uci := make([]string, len(moves))
+	for i, m := range moves {
+		uci[i] = chess.ToUCINotation(m)
+	}
+	return uci
+}
+
+// startSearch runs a search in the background, so the input loop stays
+// responsive and can stop it, and sends bestmove when it is done
+func (e *Engine) startSearch(p *chess.Position, cmd goCommand) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	e.cancel, e.searchDone = cancel, done
 
-	flush := func(s string) {
-		fmt.Fprintln(writer, s)
-		writer.Flush()
-		LogCommand("OUT", s)
+	var ponderHit chan struct{}
+	if cmd.ponder {
+		ponderHit = make(chan struct{})
+		cmd.limits.PonderHit = ponderHit
 	}
+	e.ponderHit = ponderHit
+
+	go func() {
+		defer close(done)
+
+		result := e.search(ctx, p, cmd.limits)
+		switch {
+		case cmd.infinite:
+			// bestmove may only be sent once the GUI says stop
+			<-ctx.Done()
+		case cmd.ponder:
+			// or, when pondering, once the opponent has moved
+			select {
+			case <-ctx.Done():
+			case <-ponderHit:
+			}
+		}
+		e.send(bestMoveCommand(result))
+	}()
+}
+
+// ponderHitSearch tells a "go ponder" search that the opponent played the
+// move it was pondering on, so it goes on as a normal timed search
+func (e *Engine) ponderHitSearch() {
+	if e.ponderHit == nil {
+		return
+	}
+	close(e.ponderHit)
+	e.ponderHit = nil
+}
+
+// stopSearch stops the running search, if any, and waits for it to send
+// its bestmove
+func (e *Engine) stopSearch() {
+	if e.cancel == nil {
+		return
+	}
+	e.cancel()
+	<-e.searchDone
+	e.cancel, e.searchDone, e.ponderHit = nil, nil, nil
+}
+
+// Run talks UCI over stdin and stdout until "quit" or the end of input
+func (e *Engine) Run(p *chess.Position) {
+	e.Loop(p, os.Stdin, os.Stdout)
+}
+
+// Loop reads UCI commands from in and writes the responses to out until
+// "quit" or the end of input. Searches run in the background while the
+// loop keeps reading, so "stop", "isready" and "quit" are handled at once.
+func (e *Engine) Loop(p *chess.Position, in io.Reader, out io.Writer) {
+	scanner := bufio.NewScanner(in)
+	e.out = newLineWriter(out)
+	defer e.stopSearch()
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -96,15 +284,25 @@ func (e *Engine) Run(p *chess.Position) {
 
 		switch fields[0] {
 		case "uci":
-			flush("id name GoHobbyEngine")
-			flush("id author Liam Hatcher")
-			flush("uciok")
+			e.send("id name GoHobbyEngine")
+			e.send("id author Liam Hatcher")
+			for _, o := range options {
+				e.send(o.declaration())
+			}
+			e.send("uciok")
 		case "isready":
-			flush("readyok")
+			e.send("readyok")
 		case "ucinewgame":
+			e.stopSearch()
 			e.MoveHistory = []string{}
 			e.FirstMoveDone = false
 			e.EngineColor = NotInitialized
+			e.searcher.TT.Clear()
+		case "setoption":
+			e.stopSearch()
+			if err := e.setOption(fields); err != nil {
+				e.diagnostic(err.Error())
+			}
 		case "position":
 			e.MoveHistory = getUCIMoves(fields)
 			if e.EngineColor == NotInitialized {
@@ -114,15 +312,39 @@ func (e *Engine) Run(p *chess.Position) {
 					e.EngineColor = Black
 				}
 			}
-			if len(e.MoveHistory) > 0 {
-				lastMove := e.MoveHistory[len(e.MoveHistory)-1]
-				p.ApplyMove(lastMove)
+			// replay the game from the start, rejecting any move that isn't
+			// legal in the position it is played in
+			*p = *chess.NewPosition()
+			for i, uciMove := range e.MoveHistory {
+				move, err := chess.ParseUCIMove(uciMove)
+				if err != nil || !p.IsPseudoLegal(move) || !p.IsLegal(move) {
+					e.diagnostic(fmt.Sprintf("rejecting move %s, it isn't legal in the current position", uciMove))
+					e.MoveHistory = e.MoveHistory[:i]
+					break
+				}
+				p.ApplyMove(uciMove)
 			}
+		case "bench":
+			e.stopSearch()
+			e.bench(fields)
 		case "go":
-			move := e.HandleGo(p)
-			if move != "" {
-				flush("bestmove " + move)
+			e.stopSearch()
+			cmd, err := parseGo(fields)
+			if err != nil {
+				// the GUI still waits for a bestmove, so search as for
+				// a bare "go"
+				e.diagnostic(err.Error())
+				cmd, _ = parseGo(fields[:1])
 			}
+			// the search gets its own copy, since the next "position"
+			// changes p while it may still be running
+			e.startSearch(p.Clone(), cmd)
+		case "ponderhit":
+			e.ponderHitSearch()
+		case "stop":
+			e.stopSearch()
+		case "quit":
+			return
 		}
 	}
 }
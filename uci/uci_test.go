@@ -0,0 +1,448 @@
+package uci
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/liam-hatcher/gohobbyengine/chess"
+	"github.com/liam-hatcher/gohobbyengine/search"
+)
+
+// session runs an engine's Loop in the background and talks to it like a
+// GUI would
+type session struct {
+	t     *testing.T
+	in    *io.PipeWriter
+	lines chan string
+	done  chan struct{}
+}
+
+func newSession(t *testing.T) *session {
+	t.Helper()
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+
+	s := &session{
+		t:     t,
+		in:    inWriter,
+		lines: make(chan string, 1000),
+		done:  make(chan struct{}),
+	}
+
+	go func() {
+		NewEngine().Loop(chess.NewPosition(), inReader, outWriter)
+		outWriter.Close()
+		close(s.done)
+	}()
+	go func() {
+		scanner := bufio.NewScanner(outReader)
+		for scanner.Scan() {
+			s.lines <- scanner.Text()
+		}
+		close(s.lines)
+	}()
+
+	t.Cleanup(func() {
+		inWriter.Close()
+		<-s.done
+	})
+	return s
+}
+
+func (s *session) send(command string) {
+	s.t.Helper()
+	if _, err := fmt.Fprintln(s.in, command); err != nil {
+		s.t.Fatalf("sending %q: %v", command, err)
+	}
+}
+
+// expect waits for a line starting with a match for the regular
+// expression prefix, skipping any others, and fails the test if none
+// arrives within timeout
+func (s *session) expect(prefix string, timeout time.Duration) string {
+	s.t.Helper()
+	re := regexp.MustCompile("^" + prefix)
+	deadline := time.After(timeout)
+	for {
+		select {
+		case line, ok := <-s.lines:
+			if !ok {
+				s.t.Fatalf("expected %q, but the engine stopped", prefix)
+			}
+			if re.MatchString(line) {
+				return line
+			}
+		case <-deadline:
+			s.t.Fatalf("expected %q within %v", prefix, timeout)
+		}
+	}
+}
+
+func TestGoSendsBestmove(t *testing.T) {
+	s := newSession(t)
+	s.send("position startpos moves e2e4")
+	s.send("go depth 3")
+
+	line := s.expect("bestmove", 5*time.Second)
+	move, err := chess.ParseUCIMove(strings.Fields(line)[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pos := chess.NewPosition()
+	pos.ApplyMove("e2e4")
+	if !pos.IsPseudoLegal(move) || !pos.IsLegal(move) {
+		t.Errorf("expected a legal move for black, got %s", line)
+	}
+}
+
+func TestStopInterruptsInfiniteSearch(t *testing.T) {
+	s := newSession(t)
+	s.send("position startpos")
+	s.send("go infinite")
+
+	// the input loop keeps answering while the search runs
+	time.Sleep(100 * time.Millisecond)
+	s.send("isready")
+	if line := s.expect("", time.Second); line != "readyok" && !strings.HasPrefix(line, "info") {
+		t.Fatalf("expected readyok while searching, got %q", line)
+	}
+	s.expect("readyok", time.Second)
+
+	start := time.Now()
+	s.send("stop")
+	s.expect("bestmove", time.Second)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected bestmove promptly after stop, took %v", elapsed)
+	}
+}
+
+func TestInfiniteSearchWaitsForStop(t *testing.T) {
+	s := newSession(t)
+	// mate in one is found at once, but bestmove still has to wait
+	s.send("position startpos moves f2f3 e7e5 g2g4")
+	s.send("go infinite")
+	s.send("isready")
+	s.expect("readyok", time.Second)
+
+	select {
+	case line := <-s.lines:
+		for strings.HasPrefix(line, "info") {
+			select {
+			case line = <-s.lines:
+			case <-time.After(200 * time.Millisecond):
+				line = ""
+			}
+		}
+		if line != "" {
+			t.Fatalf("expected nothing but info before stop, got %q", line)
+		}
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	s.send("stop")
+	if line := s.expect("bestmove", time.Second); line != "bestmove d8h4" {
+		t.Errorf("expected bestmove d8h4, got %q", line)
+	}
+}
+
+func TestQuitStopsSearch(t *testing.T) {
+	s := newSession(t)
+	s.send("position startpos")
+	s.send("go infinite")
+	time.Sleep(50 * time.Millisecond)
+	s.send("quit")
+
+	select {
+	case <-s.done:
+	case <-time.After(time.Second):
+		t.Fatal("expected quit to end the loop")
+	}
+}
+
+func TestCommandsWhileSearching(t *testing.T) {
+	s := newSession(t)
+	s.send("position startpos")
+	s.send("go infinite")
+	// these all touch what the search uses, so they wait for it to stop
+	s.send("setoption name Hash value 2")
+	s.send("ucinewgame")
+	s.expect("bestmove", time.Second)
+
+	s.send("position startpos moves d2d4")
+	s.send("go depth 2")
+	s.send("go depth 2")
+	s.expect("bestmove", 5*time.Second)
+	s.expect("bestmove", 5*time.Second)
+
+	s.send("stop")
+	s.send("isready")
+	s.expect("readyok", time.Second)
+}
+
+func TestBestmoveIncludesPonderMove(t *testing.T) {
+	s := newSession(t)
+	s.send("position startpos")
+	s.send("go depth 4")
+
+	fields := strings.Fields(s.expect("bestmove", 5*time.Second))
+	if len(fields) != 4 || fields[2] != "ponder" {
+		t.Fatalf("expected bestmove with a ponder move, got %v", fields)
+	}
+
+	// the ponder move is a reply to the best move
+	pos := chess.NewPosition()
+	pos.ApplyMove(fields[1])
+	move, err := chess.ParseUCIMove(fields[3])
+	if err != nil || !pos.IsPseudoLegal(move) || !pos.IsLegal(move) {
+		t.Errorf("expected a legal ponder move, got %s", fields[3])
+	}
+}
+
+func TestPonderhitFinishesTheSearch(t *testing.T) {
+	s := newSession(t)
+	s.send("position startpos moves e2e4 e7e5")
+	s.send("go ponder wtime 1000 btime 1000")
+
+	// the clock alone would stop the search within a second
+	time.Sleep(50 * time.Millisecond)
+	s.send("isready")
+	s.expect("readyok", time.Second)
+	select {
+	case line := <-s.lines:
+		for strings.HasPrefix(line, "info") {
+			select {
+			case line = <-s.lines:
+			case <-time.After(1200 * time.Millisecond):
+				line = ""
+			}
+		}
+		if line != "" {
+			t.Fatalf("expected nothing but info while pondering, got %q", line)
+		}
+	case <-time.After(1200 * time.Millisecond):
+	}
+
+	s.send("ponderhit")
+	s.expect("bestmove", time.Second)
+}
+
+func TestStopWhilePondering(t *testing.T) {
+	s := newSession(t)
+	s.send("position startpos")
+	s.send("go ponder depth 1")
+	s.send("isready")
+	s.expect("readyok", time.Second)
+
+	// the search is over but bestmove waits for the GUI
+	s.send("stop")
+	s.expect("bestmove", time.Second)
+	s.send("ponderhit")
+	s.send("isready")
+	s.expect("readyok", time.Second)
+}
+
+func TestPonderOption(t *testing.T) {
+	e := NewEngine()
+	for _, value := range []string{"true", "false"} {
+		if err := e.setOption(strings.Fields("setoption name Ponder value " + value)); err != nil {
+			t.Errorf("%s: unexpected error %v", value, err)
+		}
+	}
+	if err := e.setOption(strings.Fields("setoption name Ponder value maybe")); err == nil {
+		t.Error("expected an error for an invalid check value")
+	}
+}
+
+func TestMultiPVInfo(t *testing.T) {
+	s := newSession(t)
+	s.send("setoption name MultiPV value 3")
+	s.send("position startpos")
+	s.send("go depth 3")
+
+	for k := 1; k <= 3; k++ {
+		s.expect(fmt.Sprintf(`info depth 3 seldepth \d+ multipv %d `, k), 5*time.Second)
+	}
+	s.expect("bestmove", 5*time.Second)
+}
+
+func TestSearchMovesWithInfiniteMultiPV(t *testing.T) {
+	s := newSession(t)
+	s.send("setoption name MultiPV value 2")
+	s.send("position startpos")
+	s.send("go infinite searchmoves a2a3 h2h3")
+	s.expect(`info depth 2 seldepth \d+ multipv 2 `, 5*time.Second)
+	s.send("stop")
+
+	line := s.expect("bestmove", time.Second)
+	if move := strings.Fields(line)[1]; move != "a2a3" && move != "h2h3" {
+		t.Errorf("expected one of the search moves, got %q", line)
+	}
+}
+
+func TestThreadsSearch(t *testing.T) {
+	s := newSession(t)
+	s.send("setoption name Threads value 4")
+	s.send("position startpos moves e2e4")
+	s.send("go infinite")
+	s.expect(`info depth 3 seldepth \d+ multipv 1 `, 5*time.Second)
+
+	start := time.Now()
+	s.send("stop")
+	s.expect("bestmove", time.Second)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected every thread to stop promptly, took %v", elapsed)
+	}
+
+	s.send("go depth 4")
+	s.expect("bestmove", 5*time.Second)
+}
+
+func TestUCIScore(t *testing.T) {
+	tests := []struct {
+		score    int
+		expected string
+	}{
+		{35, "cp 35"},
+		{-120, "cp -120"},
+		{search.Mate - 1, "mate 1"},
+		{search.Mate - 5, "mate 3"},
+		{-search.Mate + 4, "mate -2"},
+	}
+
+	for _, tt := range tests {
+		if got := uciScore(tt.score); got != tt.expected {
+			t.Errorf("%d: expected %q, got %q", tt.score, tt.expected, got)
+		}
+	}
+}
+
+func TestGoMate(t *testing.T) {
+	s := newSession(t)
+	// Scholar's mate is one move away
+	s.send("position startpos moves e2e4 e7e5 f1c4 b8c6 d1h5 g8f6")
+	s.send("go mate 1")
+
+	s.expect(`info depth 1 seldepth \d+ multipv 1 score mate 1 `, 5*time.Second)
+	if line := s.expect("bestmove", 5*time.Second); !strings.HasPrefix(line, "bestmove h5f7") {
+		t.Errorf("expected bestmove h5f7, got %q", line)
+	}
+}
+
+func TestInfoLines(t *testing.T) {
+	s := newSession(t)
+	s.send("position startpos")
+	s.send("go depth 2")
+
+	line := s.expect("info depth 2 ", 5*time.Second)
+	format := `^info depth 2 seldepth \d+ multipv 1 score cp -?\d+ nodes \d+ nps \d+ time \d+ hashfull \d+ pv( [a-h][1-8][a-h][1-8][nbrq]?)+$`
+	if !regexp.MustCompile(format).MatchString(line) {
+		t.Errorf("unexpected info line %q", line)
+	}
+	s.expect("bestmove", 5*time.Second)
+}
+
+func TestDiagnosticsAreInfoStrings(t *testing.T) {
+	s := newSession(t)
+	s.send("setoption name Colour value blue")
+	s.expect(`info string unknown option "Colour"`, time.Second)
+	s.send("go depth x")
+	s.expect(`info string invalid value "x" for depth`, time.Second)
+	s.expect("bestmove", 5*time.Second)
+}
+
+func TestLineWriterKeepsLinesWhole(t *testing.T) {
+	var out strings.Builder
+	w := newLineWriter(&out)
+
+	line := strings.Repeat("x", 5000)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				w.send(line)
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 160 {
+		t.Fatalf("expected 160 lines, got %d", len(lines))
+	}
+	for _, l := range lines {
+		if l != line {
+			t.Fatal("expected every line to be written whole")
+		}
+	}
+}
+
+func TestStrengthOptions(t *testing.T) {
+	e := NewEngine()
+	if e.skill() != nil {
+		t.Error("expected full strength by default")
+	}
+
+	set := func(name, value string) {
+		t.Helper()
+		if err := e.setOption(strings.Fields("setoption name " + name + " value " + value)); err != nil {
+			t.Fatalf("%s: unexpected error %v", name, err)
+		}
+	}
+
+	set("Skill Level", "5")
+	if sk := e.skill(); sk == nil || sk.Level() != 5 {
+		t.Errorf("expected level 5, got %v", sk)
+	}
+
+	// UCI_Elo only counts with UCI_LimitStrength
+	set("UCI_Elo", "2000")
+	if sk := e.skill(); sk == nil || sk.Level() != 5 {
+		t.Errorf("expected UCI_Elo to be ignored, got %v", sk)
+	}
+	set("UCI_LimitStrength", "true")
+	if sk := e.skill(); sk == nil || sk.Level() != search.SkillFromElo(2000).Level() {
+		t.Errorf("expected the level for 2000 Elo, got %v", sk)
+	}
+
+	for _, command := range []string{
+		"setoption name Skill Level value 21",
+		"setoption name UCI_Elo value 1000",
+		"setoption name UCI_LimitStrength value yes",
+	} {
+		if err := e.setOption(strings.Fields(command)); err == nil {
+			t.Errorf("%s: expected an error", command)
+		}
+	}
+}
+
+func TestWeakenedSearch(t *testing.T) {
+	s := newSession(t)
+	s.send("setoption name Skill Level value 0")
+	s.send("position startpos")
+	s.send("go depth 10")
+
+	// level 0 stops after the first iteration, and only reports the one
+	// line asked for of those it considers
+	s.expect(`info depth 1 seldepth \d+ multipv 1 `, 5*time.Second)
+	s.send("isready")
+	for {
+		line := s.expect("", 5*time.Second)
+		if strings.HasPrefix(line, "info depth 2 ") {
+			t.Fatal("expected no deeper iterations")
+		}
+		if strings.Contains(line, " multipv 2 ") {
+			t.Fatalf("expected only the line asked for, got %q", line)
+		}
+		if strings.HasPrefix(line, "bestmove") {
+			break
+		}
+	}
+}
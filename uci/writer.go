@@ -0,0 +1,29 @@
+package uci
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// lineWriter sends whole lines to the GUI. The input loop and the search
+// goroutine both write to it, and the lock keeps one from writing in the
+// middle of the other's line.
+type lineWriter struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+func newLineWriter(w io.Writer) *lineWriter {
+	return &lineWriter{w: bufio.NewWriter(w)}
+}
+
+// send writes line and flushes it at once, since the GUI waits for it
+func (lw *lineWriter) send(line string) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	fmt.Fprintln(lw.w, line)
+	lw.w.Flush()
+	LogCommand("OUT", line)
+}